@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	"gopkg.in/yaml.v3"
+)
+
+const outputTable = "table"
+
+var outputFormats = append([]string{outputTable}, discovery.ReportFormats...)
+
+type options struct {
+	output  string
+	outPath string
+	// explain lists why each api and stage was checked or left out after the table
+	explain bool
+	// partition and stsRegion are the settings of the provider of the same name
+	partition string
+	stsRegion string
+}
+
+// stringList is a repeatable flag of comma separated items.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// parseArgs adds the account of the flags to the configuration file, the other flags
+// override its options.
+func parseArgs(args []string, stderr io.Writer) (*discovery.Config, *options, error) {
+	flags := flag.NewFlagSet("awsapigateway-audit", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: awsapigateway-audit [flags]\n\n"+
+			"Checks the logging settings of API Gateway stages and lists the log groups carrying their traffic.\n\n")
+		flags.PrintDefaults()
+	}
+
+	var (
+		configPath, region, roleArn, timeout, failOn  string
+		exclude, ignoreAccessLogSettings, strict      bool
+		failOnUnmatched                               bool
+		apiList, includeApis, excludeApis, severities stringList
+		opts                                          options
+	)
+	flags.StringVar(&configPath, "config", "", "YAML file with the accounts and options, using the arguments of the resource as keys")
+	flags.StringVar(&region, "region", "", "region of the account to check")
+	flags.Var(&includeApis, "include-api", "api id or name, or api/stage, to check, with * and ? wildcards, repeatable or comma separated")
+	flags.Var(&excludeApis, "exclude-api", "api id or name, or api/stage, to leave out, with * and ? wildcards, repeatable or comma separated")
+	flags.Var(&apiList, "api", "deprecated, use -include-api: api id or apiId/stageName to check, repeatable or comma separated")
+	flags.StringVar(&roleArn, "role-arn", "", "role assumed to read the account")
+	flags.StringVar(&opts.stsRegion, "sts-region", "", "region of the STS endpoint assuming the roles of the accounts without sts_region, defaults to their region")
+	flags.StringVar(&opts.partition, "partition", "", "partition of the accounts: "+strings.Join(discovery.Partitions, ", ")+", defaults to the partition of their region")
+	flags.BoolVar(&exclude, "exclude", false, "deprecated, use -exclude-api: check every api except the ones given with -api")
+	flags.BoolVar(&ignoreAccessLogSettings, "ignore-access-log-settings", false, "do not check the access log settings")
+	flags.BoolVar(&strict, "strict-log-group-format", false, "leave out log groups receiving different access log keys")
+	flags.StringVar(&timeout, "timeout", "1m", "timeout of the audit")
+	flags.StringVar(&failOn, "fail-on", string(discovery.FailOnAnyError), "when account errors fail the audit: "+strings.Join(discovery.FailOnModes, ", "))
+	flags.BoolVar(&failOnUnmatched, "fail-on-unmatched", false, "report api entries matching no api or stage as errors instead of warnings")
+	flags.Var(&severities, "severity", "code=severity overriding the severity of a finding code, repeatable")
+	flags.StringVar(&opts.output, "output", outputTable, "output format: "+strings.Join(outputFormats, ", "))
+	flags.StringVar(&opts.outPath, "out", "", "file to write the output to instead of stdout")
+	flags.BoolVar(&opts.explain, "explain", false, "explain why each api and stage was checked or left out, with the table output")
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, nil, fmt.Errorf("unexpected arguments %s", discovery.StringFromArray(flags.Args()))
+	}
+
+	config := &discovery.Config{}
+	if configPath != "" {
+		content, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf("parsing %s: %w", configPath, err)
+		}
+	}
+
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if region != "" {
+		config.Accounts = append(config.Accounts, discovery.AccountConfig{
+			Region:              region,
+			IncludeApis:         includeApis,
+			ExcludeApis:         excludeApis,
+			ApiList:             append([]string{}, apiList...),
+			CrossAccountRoleArn: roleArn,
+			Exclude:             exclude,
+		})
+	} else if set["include-api"] || set["exclude-api"] || set["api"] || set["role-arn"] || set["exclude"] {
+		return nil, nil, errors.New("-include-api, -exclude-api, -api, -role-arn and -exclude need -region")
+	}
+	if set["ignore-access-log-settings"] {
+		config.IgnoreAccessLogSettings = ignoreAccessLogSettings
+	}
+	if set["strict-log-group-format"] {
+		config.StrictLogGroupFormat = strict
+	}
+	if set["fail-on-unmatched"] {
+		config.FailOnUnmatched = failOnUnmatched
+	}
+	if set["timeout"] || config.Timeout == "" {
+		config.Timeout = timeout
+	}
+	if set["fail-on"] || config.FailOn == "" {
+		config.FailOn = failOn
+	}
+	for _, severity := range severities {
+		code, value, found := strings.Cut(severity, "=")
+		if !found {
+			return nil, nil, fmt.Errorf("-severity %q is not code=severity", severity)
+		}
+		if config.FindingSeverity == nil {
+			config.FindingSeverity = make(map[string]string)
+		}
+		config.FindingSeverity[code] = value
+	}
+
+	if len(config.Accounts) == 0 {
+		return nil, nil, errors.New("no accounts to check, use -region or -config")
+	}
+	for i, account := range config.Accounts {
+		if account.Region == "" {
+			return nil, nil, fmt.Errorf("account %d has no region", i)
+		}
+		if err := account.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("account %d: %w", i, err)
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if opts.stsRegion != "" {
+		if err := discovery.ValidateRegion(opts.stsRegion); err != nil {
+			return nil, nil, fmt.Errorf("-sts-region %w", err)
+		}
+	}
+	if opts.partition != "" && !slices.Contains(discovery.Partitions, opts.partition) {
+		return nil, nil, fmt.Errorf("-partition %q is not one of %s", opts.partition, discovery.StringFromArray(discovery.Partitions))
+	}
+	if !slices.Contains(outputFormats, opts.output) {
+		return nil, nil, fmt.Errorf("-output %q is not one of %s", opts.output, discovery.StringFromArray(outputFormats))
+	}
+	if config.ReportFormat == "" {
+		config.ReportFormat = string(discovery.ReportFormatJson)
+	}
+	return config, &opts, nil
+}
@@ -0,0 +1,140 @@
+// Command awsapigateway-audit checks the logging settings of API Gateway stages without a
+// Terraform workspace. It exits with 1 when the audit finds errors, 2 on invalid arguments.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+)
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	config, opts, err := parseArgs(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	} else if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	discoveryOpts, err := config.Options()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: timeout: %v\n", err)
+		return 2
+	}
+	discoveryOpts.Partition = discovery.Partition(opts.partition)
+	discoveryOpts.StsRegion = opts.stsRegion
+	specs := config.Specs()
+	result, err := discovery.Discover(ctx, specs, discoveryOpts)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+	failed := discoveryOpts.FailOn.Fails(len(result.FailedAccounts), len(specs))
+	printFindings(stderr, result, failed)
+
+	var content []byte
+	if opts.output == outputTable {
+		content = table(result, opts.explain)
+	} else {
+		content, err = result.Encode(discovery.ReportFormat(opts.output))
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+	if opts.outPath != "" {
+		err = os.WriteFile(opts.outPath, content, 0o644)
+	} else {
+		_, err = stdout.Write(content)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	// report_path writes the report as the provider does, next to the output
+	if config.ReportPath != "" {
+		if err := result.WriteReport(config.ReportPath, discovery.ReportFormat(config.ReportFormat)); err != nil {
+			fmt.Fprintf(stderr, "Error: writing the report to %s: %v\n", config.ReportPath, err)
+			return 1
+		}
+	}
+	if result.HasErrors() || failed {
+		return 1
+	}
+	return 0
+}
+
+func table(result *discovery.Result, explain bool) []byte {
+	var builder strings.Builder
+	writer := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ACCOUNT\tREGION\tAPI\tSTAGE\tTYPE\tSTATUS\tFINDINGS")
+	for _, stage := range result.Stages {
+		var codes []string
+		for _, finding := range stage.Findings {
+			if finding.Severity != discovery.FindingSeverityIgnore {
+				codes = append(codes, finding.Code)
+			}
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", valueOrDash(stage.AccountId), stage.Region, stage.ApiId,
+			stage.StageName, stage.ApiType, stage.Status, valueOrDash(strings.Join(codes, ",")))
+	}
+	for _, failedAccount := range result.FailedAccounts {
+		fmt.Fprintf(writer, "%s\t%s\t-\t-\t-\terror\t%s\n", valueOrDash(failedAccount.AccountId), failedAccount.Region, failedAccount.Error)
+	}
+	_ = writer.Flush()
+
+	builder.WriteString("\nLog groups:\n")
+	for _, logGroupName := range result.LogGroupNames {
+		builder.WriteString("  " + logGroupName + "\n")
+	}
+	if explain {
+		builder.WriteString("\nSelection:\n")
+		for _, selection := range result.Selections {
+			fmt.Fprintf(&builder, "  %s %s\n", discovery.AccountName(selection.AccountId, selection.Region), selection)
+		}
+	}
+	return []byte(builder.String())
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// printFindings writes the findings the way Terraform shows them.
+func printFindings(w io.Writer, result *discovery.Result, failAccounts bool) {
+	for _, failedAccount := range result.FailedAccounts {
+		severity := "Warning"
+		if failAccounts {
+			severity = "Error"
+		}
+		fmt.Fprintf(w, "%s: %s in %s\n", severity, failedAccount.Error, discovery.AccountName(failedAccount.AccountId, failedAccount.Region))
+	}
+	for _, finding := range result.AllFindings() {
+		if finding.Severity == discovery.FindingSeverityIgnore {
+			continue
+		}
+		severity := "Warning"
+		if finding.Severity == discovery.FindingSeverityError {
+			severity = "Error"
+		}
+		fmt.Fprintf(w, "%s: %s\n", severity, finding.Message)
+		if finding.Detail != "" {
+			fmt.Fprintf(w, "  %s\n", strings.ReplaceAll(finding.Detail, "\n", "\n  "))
+		}
+	}
+}
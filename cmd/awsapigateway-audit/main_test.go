@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery/cassette"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseArgs(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "audit.yaml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(`
+timeout: 30s
+strict_log_group_format: true
+finding_severity:
+  execution_log_method_override: ignore
+accounts:
+  - region: eu-west-1
+    api_list: [api3]
+    cross_account_role_arn: arn:aws:iam::123456789012:role/traceable
+`), 0o600))
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected *discovery.Config
+		output   string
+		err      bool
+	}{
+		{
+			name: "flags",
+			args: []string{"-region", "us-east-1", "-api", "api1,api2/dev", "-api", "api3", "-exclude", "-output", "sarif", "-fail-on-unmatched"},
+			expected: &discovery.Config{
+				Timeout:         "1m",
+				FailOn:          "any_error",
+				FailOnUnmatched: true,
+				ReportFormat:    "json",
+				Accounts:        []discovery.AccountConfig{{Region: "us-east-1", ApiList: []string{"api1", "api2/dev", "api3"}, Exclude: true}},
+			},
+			output: "sarif",
+		},
+		{
+			name: "config file with flags",
+			args: []string{"-config", configPath, "-region", "us-east-1", "-api", "api1", "-fail-on", "never", "-severity", "wrong_syntax=warning"},
+			expected: &discovery.Config{
+				StrictLogGroupFormat: true,
+				Timeout:              "30s",
+				FailOn:               "never",
+				ReportFormat:         "json",
+				FindingSeverity:      map[string]string{"execution_log_method_override": "ignore", "wrong_syntax": "warning"},
+				Accounts: []discovery.AccountConfig{
+					{Region: "eu-west-1", ApiList: []string{"api3"}, CrossAccountRoleArn: "arn:aws:iam::123456789012:role/traceable"},
+					{Region: "us-east-1", ApiList: []string{"api1"}},
+				},
+			},
+			output: "table",
+		},
+		{
+			name: "include and exclude lists",
+			args: []string{"-region", "us-east-1", "-include-api", "orders-*", "-exclude-api", "orders-*/test,legacy", "-explain"},
+			expected: &discovery.Config{
+				Timeout:      "1m",
+				FailOn:       "any_error",
+				ReportFormat: "json",
+				Accounts: []discovery.AccountConfig{{
+					Region:      "us-east-1",
+					IncludeApis: []string{"orders-*"},
+					ExcludeApis: []string{"orders-*/test", "legacy"},
+					ApiList:     []string{},
+				}},
+			},
+			output: "table",
+		},
+		{name: "no accounts", args: []string{"-api", "api1"}, err: true},
+		{name: "include list without region", args: []string{"-include-api", "api1"}, err: true},
+		{name: "unknown output", args: []string{"-region", "us-east-1", "-output", "xml"}, err: true},
+		{name: "unknown finding code", args: []string{"-region", "us-east-1", "-severity", "unknown=error"}, err: true},
+		{name: "unknown fail_on", args: []string{"-region", "us-east-1", "-fail-on", "sometimes"}, err: true},
+		{name: "invalid sts region", args: []string{"-region", "us-east-1", "-include-api", "api1", "-sts-region", "us-east"}, err: true},
+		{name: "unknown partition", args: []string{"-region", "us-east-1", "-include-api", "api1", "-partition", "aws-eu"}, err: true},
+		{name: "invalid region", args: []string{"-region", "us-east1", "-include-api", "api1"}, err: true},
+		{name: "invalid role arn", args: []string{"-region", "us-east-1", "-include-api", "api1", "-role-arn", "traceable"}, err: true},
+		{name: "invalid api entry", args: []string{"-region", "us-east-1", "-include-api", "api1/dev/x"}, err: true},
+		{name: "timeout out of range", args: []string{"-region", "us-east-1", "-include-api", "api1", "-timeout", "2h"}, err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, opts, err := parseArgs(test.args, io.Discard)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, config)
+			assert.Equal(t, test.output, opts.output)
+		})
+	}
+}
+
+func TestRunReportPath(t *testing.T) {
+	// replays the responses recorded for the discovery tests, no request reaches AWS
+	t.Setenv(discovery.ReplayCassetteEnv, "../../pkg/discovery/testdata/cassettes/two-accounts.yaml")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+	dir := t.TempDir()
+	configPath, reportPath := filepath.Join(dir, "audit.yaml"), filepath.Join(dir, "report.csv")
+	assert.NoError(t, os.WriteFile(configPath, []byte(`
+report_path: `+reportPath+`
+report_format: csv
+fail_on: never
+accounts:
+  - region: us-east-1
+    api_list: []
+    exclude: true
+`), 0o600))
+
+	var stdout strings.Builder
+	run(context.Background(), []string{"-config", configPath}, &stdout, io.Discard)
+	assert.Contains(t, stdout.String(), cassette.Anonymize(cassette.KindLogGroup, "pets-access-logs"))
+	report, err := os.ReadFile(reportPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(report), cassette.Anonymize(cassette.KindApiId, "rest1")+","+cassette.Anonymize(cassette.KindStageName, "dev"))
+}
+
+func TestTable(t *testing.T) {
+	result := &discovery.Result{
+		LogGroupNames: []string{"API-Gateway-Execution-Logs_api1/dev"},
+		Stages: []discovery.StageResult{{
+			AccountId: "123456789012",
+			Region:    "us-east-1",
+			ApiId:     "api1",
+			StageName: "dev",
+			ApiType:   "REST",
+			Status:    "warning",
+			Findings: []discovery.Finding{
+				{Code: "execution_log_method_override", Severity: "warning"},
+				{Code: "access_log_not_enabled_rest", Severity: "ignore"},
+			},
+		}},
+		FailedAccounts: []discovery.FailedAccount{{Region: "eu-west-1", Error: "access denied"}},
+	}
+
+	assert.Equal(t, ""+
+		"ACCOUNT       REGION     API   STAGE  TYPE  STATUS   FINDINGS\n"+
+		"123456789012  us-east-1  api1  dev    REST  warning  execution_log_method_override\n"+
+		"-             eu-west-1  -     -      -     error    access denied\n"+
+		"\n"+
+		"Log groups:\n"+
+		"  API-Gateway-Execution-Logs_api1/dev\n", string(table(result, false)))
+
+	result.Selections = []discovery.Selection{
+		{AccountId: "123456789012", Region: "us-east-1", ApiId: "api1", StageName: "dev", Selected: true, Reason: `included by include_apis[0] "api1"`},
+		{AccountId: "123456789012", Region: "us-east-1", ApiId: "api2", Reason: `excluded, no entry of include_apis matches`},
+	}
+	assert.Contains(t, string(table(result, true)), ""+
+		"  API-Gateway-Execution-Logs_api1/dev\n"+
+		"\n"+
+		"Selection:\n"+
+		"  account 123456789012 (us-east-1) api1/dev: included by include_apis[0] \"api1\"\n"+
+		"  account 123456789012 (us-east-1) api2: excluded, no entry of include_apis matches\n")
+}
@@ -0,0 +1,7 @@
+data "awsapigateway_log_groups" "example" {
+  accounts {
+    region                 = "us-east-1"
+    include_apis           = ["api1", "api2"]
+    cross_account_role_arn = ""
+  }
+}
@@ -15,9 +15,9 @@ resource "awsapigateway_resource" "traceable-example-1" {
     for_each = var.accounts
     content {
       region                 = accounts.value["region"]
-      api_list               = accounts.value["api_list"]
+      include_apis           = accounts.value["include_apis"]
+      exclude_apis           = accounts.value["exclude_apis"]
       cross_account_role_arn = accounts.value["cross_account_role_arn"]
-      exclude                = accounts.value["exclude"]
     }
   }
   timeout = "10s"
@@ -29,9 +29,8 @@ resource "awsapigateway_resource" "traceable-example-2" {
   ignore_access_log_settings = false
   accounts {
     region                 = "us-east-1"
-    api_list               = ["api1", "api2"]
+    include_apis           = ["api1", "api2"]
     cross_account_role_arn = ""
-    exclude                = false
   }
   timeout = "1s"
 }
\ No newline at end of file
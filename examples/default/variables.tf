@@ -1,9 +1,9 @@
 variable "accounts" {
   type = list(object({
     region                 = string
-    api_list               = list(string)
+    include_apis           = optional(list(string))
+    exclude_apis           = optional(list(string))
     cross_account_role_arn = string
-    exclude                = bool
   }))
 
 }
\ No newline at end of file
@@ -0,0 +1,3 @@
+output "access_log_group" {
+  value = provider::awsapigateway::access_log_group_from_arn("arn:aws:logs:us-east-1:123456789012:log-group:access-logs")
+}
@@ -0,0 +1,3 @@
+output "execution_log_group" {
+  value = provider::awsapigateway::execution_log_group_name("a1b2c3d4e5", "prod")
+}
@@ -0,0 +1,7 @@
+output "access_log_keys" {
+  # { "$context.httpMethod" = "http.method", "$context.status" = "status" }
+  value = provider::awsapigateway::parse_access_log_format(jsonencode({
+    http   = { method = "$context.httpMethod" }
+    status = "$context.status"
+  }))
+}
@@ -0,0 +1,8 @@
+variable "access_log_format" {
+  type = string
+
+  validation {
+    condition     = provider::awsapigateway::validate_access_log_format(var.access_log_format, null)
+    error_message = "The access log format must be JSON and hold the variables required by Traceable."
+  }
+}
@@ -0,0 +1,6 @@
+# region:cross_account_role_arn:api_list[:exclude], accounts separated by semicolons
+terraform import awsapigateway_resource.example "us-east-1:arn:aws:iam::123456789012:role/traceable:api1,api2/dev"
+
+# JSON with the resource arguments, or @ followed by the path of a file holding it
+terraform import awsapigateway_resource.example '{"timeout":"10s","accounts":[{"region":"us-east-1","api_list":["api1"],"cross_account_role_arn":"","exclude":true}]}'
+terraform import awsapigateway_resource.example @accounts.json
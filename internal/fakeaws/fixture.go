@@ -0,0 +1,102 @@
+package fakeaws
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture describes the accounts served by the fake, requests signed with credentials that
+// were not issued by AssumeRole are served as the first account.
+type Fixture struct {
+	// PageSize bounds the pages of GetRestApis, GetApis and GetStages of apigatewayv2
+	PageSize int       `yaml:"page_size"`
+	Accounts []Account `yaml:"accounts"`
+}
+
+type Account struct {
+	AccountId string    `yaml:"account_id"`
+	Region    string    `yaml:"region"`
+	RestApis  []RestApi `yaml:"rest_apis"`
+	// HttpApis holds the HTTP and WebSocket apis, told apart by their protocol type
+	HttpApis  []HttpApi `yaml:"http_apis"`
+	LogGroups []string  `yaml:"log_groups"`
+	// Errors fail the operations of the account with an access denied error
+	Errors map[string]string `yaml:"errors"`
+}
+
+type RestApi struct {
+	Id     string      `yaml:"id"`
+	Name   string      `yaml:"name"`
+	Stages []RestStage `yaml:"stages"`
+}
+
+type RestStage struct {
+	Name              string                   `yaml:"name"`
+	MethodSettings    map[string]MethodSetting `yaml:"method_settings"`
+	AccessLogSettings *AccessLogSettings       `yaml:"access_log_settings"`
+}
+
+type HttpApi struct {
+	Id           string      `yaml:"id"`
+	Name         string      `yaml:"name"`
+	ProtocolType string      `yaml:"protocol_type"`
+	Stages       []HttpStage `yaml:"stages"`
+}
+
+type HttpStage struct {
+	Name                 string                   `yaml:"name"`
+	DefaultRouteSettings *MethodSetting           `yaml:"default_route_settings"`
+	RouteSettings        map[string]MethodSetting `yaml:"route_settings"`
+	AccessLogSettings    *AccessLogSettings       `yaml:"access_log_settings"`
+}
+
+// MethodSetting holds the logging settings of a method or a route.
+type MethodSetting struct {
+	LoggingLevel     string `yaml:"logging_level"`
+	DataTraceEnabled bool   `yaml:"data_trace_enabled"`
+}
+
+type AccessLogSettings struct {
+	DestinationArn string `yaml:"destination_arn"`
+	Format         string `yaml:"format"`
+}
+
+// LoadFixture reads a fixture, failing on unknown keys so that typos don't go unnoticed.
+func LoadFixture(fixturePath string) (*Fixture, error) {
+	file, err := os.Open(fixturePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fixture := &Fixture{}
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(fixture); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", fixturePath, err)
+	}
+	if len(fixture.Accounts) == 0 {
+		return nil, fmt.Errorf("%s has no accounts", fixturePath)
+	}
+	return fixture, nil
+}
+
+func (f *Fixture) account(accountId string, region string) *Account {
+	for i, account := range f.Accounts {
+		if account.AccountId == accountId && account.Region == region {
+			return &f.Accounts[i]
+		}
+	}
+	return nil
+}
+
+func (f *Fixture) hasAccount(accountId string) bool {
+	for _, account := range f.Accounts {
+		if account.AccountId == accountId {
+			return true
+		}
+	}
+	return false
+}
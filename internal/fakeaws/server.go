@@ -0,0 +1,441 @@
+// Package fakeaws serves the parts of API Gateway, API Gateway V2, STS and CloudWatch Logs
+// that discovery reads from YAML fixtures, so that the provider is tested without AWS.
+package fakeaws
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/internal/sigv4"
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+)
+
+// Server is a fake of AWS serving every service at a single URL.
+type Server struct {
+	URL     string
+	fixture *Fixture
+	mu      sync.Mutex
+	// identities are the callers of the credentials issued by AssumeRole, by access key
+	identities map[string]identity
+}
+
+type identity struct {
+	accountId string
+	arn       string
+}
+
+// NewServer starts a fake serving the fixture at fixturePath until the end of the test.
+func NewServer(t testing.TB, fixturePath string) *Server {
+	t.Helper()
+	fixture, err := LoadFixture(fixturePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{fixture: fixture, identities: make(map[string]identity)}
+	httpServer := httptest.NewServer(s.handler())
+	t.Cleanup(httpServer.Close)
+	s.URL = httpServer.URL
+	return s
+}
+
+// SetCredentials gives the test the static credentials of the first account.
+func (s *Server) SetCredentials(t testing.TB) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAFAKEAWS")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "fake")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /restapis", s.getRestApis)
+	mux.HandleFunc("GET /restapis/{id}/stages", s.getRestStages)
+	mux.HandleFunc("GET /v2/apis", s.getApis)
+	mux.HandleFunc("GET /v2/apis/{id}/stages", s.getHttpStages)
+	mux.HandleFunc("POST /", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("X-Amz-Target"), "Logs_20140328.") {
+			s.logs(w, r)
+		} else {
+			s.sts(w, r)
+		}
+	})
+	return mux
+}
+
+// caller returns the identity and the region of a request, the signature is not checked.
+func (s *Server) caller(r *http.Request) (identity, string) {
+	scope := sigv4.ParseScope(r)
+	accessKey, region := scope.AccessKeyId, scope.Region
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if caller, found := s.identities[accessKey]; found {
+		return caller, region
+	}
+	accountId := s.fixture.Accounts[0].AccountId
+	return identity{accountId: accountId, arn: fmt.Sprintf("arn:%s:iam::%s:user/fake", discovery.PartitionOfRegion(region), accountId)}, region
+}
+
+func (s *Server) account(r *http.Request) *Account {
+	caller, region := s.caller(r)
+	if account := s.fixture.account(caller.accountId, region); account != nil {
+		return account
+	}
+	return &Account{AccountId: caller.accountId, Region: region}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                               apigateway                                  //
+///////////////////////////////////////////////////////////////////////////////
+
+type restApiJson struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type restStageJson struct {
+	StageName         string                       `json:"stageName"`
+	MethodSettings    map[string]methodSettingJson `json:"methodSettings,omitempty"`
+	AccessLogSettings *accessLogSettingsJson       `json:"accessLogSettings,omitempty"`
+}
+
+type methodSettingJson struct {
+	LoggingLevel     string `json:"loggingLevel,omitempty"`
+	DataTraceEnabled bool   `json:"dataTraceEnabled"`
+}
+
+type accessLogSettingsJson struct {
+	DestinationArn string `json:"destinationArn,omitempty"`
+	Format         string `json:"format,omitempty"`
+}
+
+func (s *Server) getRestApis(w http.ResponseWriter, r *http.Request) {
+	account := s.account(r)
+	if message, found := account.Errors["GetRestApis"]; found {
+		writeRestJsonError(w, http.StatusForbidden, "AccessDeniedException", message)
+		return
+	}
+	items := []restApiJson{}
+	for _, api := range account.RestApis {
+		items = append(items, restApiJson{Id: api.Id, Name: api.Name})
+	}
+	items, position, err := page(items, s.fixture.PageSize, r.URL.Query().Get("limit"), r.URL.Query().Get("position"))
+	if err != nil {
+		writeRestJsonError(w, http.StatusBadRequest, "BadRequestException", err.Error())
+		return
+	}
+	body := map[string]any{"item": items}
+	if position != "" {
+		body["position"] = position
+	}
+	writeJson(w, "application/json", body)
+}
+
+func (s *Server) getRestStages(w http.ResponseWriter, r *http.Request) {
+	account := s.account(r)
+	if message, found := account.Errors["GetStages"]; found {
+		writeRestJsonError(w, http.StatusForbidden, "AccessDeniedException", message)
+		return
+	}
+	for _, api := range account.RestApis {
+		if api.Id != r.PathValue("id") {
+			continue
+		}
+		items := []restStageJson{}
+		for _, stage := range api.Stages {
+			item := restStageJson{
+				StageName:         stage.Name,
+				MethodSettings:    methodSettingsJson(stage.MethodSettings),
+				AccessLogSettings: accessLogSettingsOf(stage.AccessLogSettings),
+			}
+			items = append(items, item)
+		}
+		writeJson(w, "application/json", map[string]any{"item": items})
+		return
+	}
+	writeRestJsonError(w, http.StatusNotFound, "NotFoundException", "Invalid API identifier specified")
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                              apigatewayv2                                 //
+///////////////////////////////////////////////////////////////////////////////
+
+type apiJson struct {
+	ApiId        string `json:"apiId"`
+	Name         string `json:"name"`
+	ProtocolType string `json:"protocolType"`
+}
+
+type httpStageJson struct {
+	StageName            string                       `json:"stageName"`
+	DefaultRouteSettings *methodSettingJson           `json:"defaultRouteSettings,omitempty"`
+	RouteSettings        map[string]methodSettingJson `json:"routeSettings,omitempty"`
+	AccessLogSettings    *accessLogSettingsJson       `json:"accessLogSettings,omitempty"`
+}
+
+func (s *Server) getApis(w http.ResponseWriter, r *http.Request) {
+	account := s.account(r)
+	if message, found := account.Errors["GetApis"]; found {
+		writeRestJsonError(w, http.StatusForbidden, "AccessDeniedException", message)
+		return
+	}
+	items := []apiJson{}
+	for _, api := range account.HttpApis {
+		items = append(items, apiJson{ApiId: api.Id, Name: api.Name, ProtocolType: api.ProtocolType})
+	}
+	writePageV2(w, r, items, s.fixture.PageSize)
+}
+
+func (s *Server) getHttpStages(w http.ResponseWriter, r *http.Request) {
+	account := s.account(r)
+	if message, found := account.Errors["GetStages"]; found {
+		writeRestJsonError(w, http.StatusForbidden, "AccessDeniedException", message)
+		return
+	}
+	for _, api := range account.HttpApis {
+		if api.Id != r.PathValue("id") {
+			continue
+		}
+		items := []httpStageJson{}
+		for _, stage := range api.Stages {
+			item := httpStageJson{
+				StageName:         stage.Name,
+				RouteSettings:     methodSettingsJson(stage.RouteSettings),
+				AccessLogSettings: accessLogSettingsOf(stage.AccessLogSettings),
+			}
+			if stage.DefaultRouteSettings != nil {
+				item.DefaultRouteSettings = &methodSettingJson{
+					LoggingLevel:     stage.DefaultRouteSettings.LoggingLevel,
+					DataTraceEnabled: stage.DefaultRouteSettings.DataTraceEnabled,
+				}
+			}
+			items = append(items, item)
+		}
+		writePageV2(w, r, items, s.fixture.PageSize)
+		return
+	}
+	writeRestJsonError(w, http.StatusNotFound, "NotFoundException", "Invalid API identifier specified")
+}
+
+// writePageV2 writes the page of maxResults and nextToken.
+func writePageV2[T any](w http.ResponseWriter, r *http.Request, items []T, pageSize int) {
+	items, nextToken, err := page(items, pageSize, r.URL.Query().Get("maxResults"), r.URL.Query().Get("nextToken"))
+	if err != nil {
+		writeRestJsonError(w, http.StatusBadRequest, "BadRequestException", err.Error())
+		return
+	}
+	body := map[string]any{"items": items}
+	if nextToken != "" {
+		body["nextToken"] = nextToken
+	}
+	writeJson(w, "application/json", body)
+}
+
+// page returns the items from the index of token and the token of the next page.
+func page[T any](items []T, pageSize int, limit string, token string) ([]T, string, error) {
+	start := 0
+	if token != "" {
+		var err error
+		if start, err = strconv.Atoi(token); err != nil || start < 0 || start > len(items) {
+			return nil, "", fmt.Errorf("invalid pagination token %q", token)
+		}
+	}
+	size := len(items) - start
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return nil, "", fmt.Errorf("invalid limit %q", limit)
+		}
+		size = min(size, n)
+	}
+	if pageSize > 0 {
+		size = min(size, pageSize)
+	}
+	end := start + size
+	if end == len(items) {
+		return items[start:end], "", nil
+	}
+	return items[start:end], strconv.Itoa(end), nil
+}
+
+func methodSettingsJson(settings map[string]MethodSetting) map[string]methodSettingJson {
+	if settings == nil {
+		return nil
+	}
+	items := make(map[string]methodSettingJson, len(settings))
+	for key, setting := range settings {
+		items[key] = methodSettingJson{LoggingLevel: setting.LoggingLevel, DataTraceEnabled: setting.DataTraceEnabled}
+	}
+	return items
+}
+
+func accessLogSettingsOf(settings *AccessLogSettings) *accessLogSettingsJson {
+	if settings == nil {
+		return nil
+	}
+	return &accessLogSettingsJson{DestinationArn: settings.DestinationArn, Format: settings.Format}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                   sts                                     //
+///////////////////////////////////////////////////////////////////////////////
+
+type stsCredentials struct {
+	AccessKeyId     string `xml:"AccessKeyId"`
+	SecretAccessKey string `xml:"SecretAccessKey"`
+	SessionToken    string `xml:"SessionToken"`
+	Expiration      string `xml:"Expiration"`
+}
+
+type assumeRoleResponse struct {
+	XMLName         xml.Name `xml:"https://sts.amazonaws.com/doc/2011-06-15/ AssumeRoleResponse"`
+	AssumedRoleUser struct {
+		Arn           string `xml:"Arn"`
+		AssumedRoleId string `xml:"AssumedRoleId"`
+	} `xml:"AssumeRoleResult>AssumedRoleUser"`
+	Credentials stsCredentials `xml:"AssumeRoleResult>Credentials"`
+	RequestId   string         `xml:"ResponseMetadata>RequestId"`
+}
+
+type getCallerIdentityResponse struct {
+	XMLName   xml.Name `xml:"https://sts.amazonaws.com/doc/2011-06-15/ GetCallerIdentityResponse"`
+	Arn       string   `xml:"GetCallerIdentityResult>Arn"`
+	UserId    string   `xml:"GetCallerIdentityResult>UserId"`
+	Account   string   `xml:"GetCallerIdentityResult>Account"`
+	RequestId string   `xml:"ResponseMetadata>RequestId"`
+}
+
+type stsErrorResponse struct {
+	XMLName   xml.Name `xml:"ErrorResponse"`
+	Type      string   `xml:"Error>Type"`
+	Code      string   `xml:"Error>Code"`
+	Message   string   `xml:"Error>Message"`
+	RequestId string   `xml:"RequestId"`
+}
+
+func (s *Server) sts(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeStsError(w, "InvalidParameterValue", err.Error())
+		return
+	}
+	switch r.PostForm.Get("Action") {
+	case "GetCallerIdentity":
+		caller, _ := s.caller(r)
+		writeXml(w, getCallerIdentityResponse{Arn: caller.arn, UserId: "AIDAFAKEAWS", Account: caller.accountId, RequestId: "fake"})
+	case "AssumeRole":
+		s.assumeRole(w, r)
+	default:
+		writeStsError(w, "InvalidAction", "Could not find operation "+r.PostForm.Get("Action"))
+	}
+}
+
+// assumeRole issues credentials for any role of an account of the fixture.
+func (s *Server) assumeRole(w http.ResponseWriter, r *http.Request) {
+	roleArn := r.PostForm.Get("RoleArn")
+	_, region := s.caller(r)
+	parsed, err := discovery.ParseArn(roleArn)
+	// roles are only assumed by callers of their partition
+	if err != nil || !strings.HasPrefix(parsed.Resource, "role/") || !s.fixture.hasAccount(parsed.AccountID) ||
+		discovery.Partition(parsed.Partition) != discovery.PartitionOfRegion(region) {
+		writeStsError(w, "AccessDenied", fmt.Sprintf("Not authorized to perform sts:AssumeRole on resource %s", roleArn))
+		return
+	}
+	accountId, roleName := parsed.AccountID, strings.TrimPrefix(parsed.Resource, "role/")
+	sessionName := r.PostForm.Get("RoleSessionName")
+
+	response := assumeRoleResponse{RequestId: "fake"}
+	response.AssumedRoleUser.Arn = fmt.Sprintf("arn:%s:sts::%s:assumed-role/%s/%s", parsed.Partition, accountId, roleName, sessionName)
+	response.AssumedRoleUser.AssumedRoleId = "AROAFAKEAWS:" + sessionName
+	s.mu.Lock()
+	response.Credentials = stsCredentials{
+		AccessKeyId:     fmt.Sprintf("ASIAFAKEAWS%d", len(s.identities)),
+		SecretAccessKey: "fake",
+		SessionToken:    "fake",
+		Expiration:      time.Now().UTC().Add(time.Hour).Format(time.RFC3339),
+	}
+	s.identities[response.Credentials.AccessKeyId] = identity{accountId: accountId, arn: response.AssumedRoleUser.Arn}
+	s.mu.Unlock()
+	writeXml(w, response)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                  logs                                     //
+///////////////////////////////////////////////////////////////////////////////
+
+type logGroupJson struct {
+	LogGroupName string `json:"logGroupName"`
+	Arn          string `json:"arn"`
+}
+
+func (s *Server) logs(w http.ResponseWriter, r *http.Request) {
+	operation := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "Logs_20140328.")
+	if operation != "DescribeLogGroups" {
+		writeJsonError(w, "UnknownOperationException", "Could not find operation "+operation)
+		return
+	}
+	var input struct {
+		LogGroupNamePrefix string `json:"logGroupNamePrefix"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeJsonError(w, "InvalidParameterException", err.Error())
+		return
+	}
+	account := s.account(r)
+	if message, found := account.Errors[operation]; found {
+		writeJsonError(w, "AccessDeniedException", message)
+		return
+	}
+	logGroups := []logGroupJson{}
+	for _, name := range account.LogGroups {
+		if strings.HasPrefix(name, input.LogGroupNamePrefix) {
+			logGroups = append(logGroups, logGroupJson{
+				LogGroupName: name,
+				Arn:          discovery.LogGroupArn(discovery.PartitionOfRegion(account.Region), account.Region, account.AccountId, name) + ":*",
+			})
+		}
+	}
+	writeJson(w, "application/x-amz-json-1.1", map[string]any{"logGroups": logGroups})
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                responses                                  //
+///////////////////////////////////////////////////////////////////////////////
+
+func writeJson(w http.ResponseWriter, contentType string, body any) {
+	w.Header().Set("Content-Type", contentType)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeXml(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "text/xml")
+	_ = xml.NewEncoder(w).Encode(body)
+}
+
+func writeRestJsonError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Amzn-Errortype", code)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func writeJsonError(w http.ResponseWriter, code string, message string) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{"__type": code, "message": message})
+}
+
+func writeStsError(w http.ResponseWriter, code string, message string) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusForbidden)
+	_ = xml.NewEncoder(w).Encode(stsErrorResponse{Type: "Sender", Code: code, Message: message, RequestId: "fake"})
+}
@@ -0,0 +1,120 @@
+package fakeaws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	v1 "github.com/aws/aws-sdk-go-v2/service/apigateway"
+	v2 "github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer(t, "testdata/accounts.yaml")
+	server.SetCredentials(t)
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
+	assert.NoError(t, err)
+	endpoint := aws.String(server.URL)
+
+	apiGateway := v1.NewFromConfig(cfg, func(o *v1.Options) { o.BaseEndpoint = endpoint })
+	restApis, err := apiGateway.GetRestApis(ctx, &v1.GetRestApisInput{})
+	assert.NoError(t, err)
+	assert.Len(t, restApis.Items, 1)
+	assert.Nil(t, restApis.Position)
+	restStages, err := apiGateway.GetStages(ctx, &v1.GetStagesInput{RestApiId: aws.String("rest1")})
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", aws.ToString(restStages.Item[0].StageName))
+	assert.Equal(t, "INFO", aws.ToString(restStages.Item[0].MethodSettings["*/*"].LoggingLevel))
+	assert.True(t, restStages.Item[0].MethodSettings["*/*"].DataTraceEnabled)
+	assert.Equal(t, "arn:aws:logs:us-east-1:123456789012:log-group:access-logs", aws.ToString(restStages.Item[0].AccessLogSettings.DestinationArn))
+	_, err = apiGateway.GetStages(ctx, &v1.GetStagesInput{RestApiId: aws.String("unknown")})
+	assert.ErrorContains(t, err, "NotFoundException")
+
+	apiGatewayV2 := v2.NewFromConfig(cfg, func(o *v2.Options) { o.BaseEndpoint = endpoint })
+	apis, err := apiGatewayV2.GetApis(ctx, &v2.GetApisInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP", string(apis.Items[0].ProtocolType))
+	_, err = apiGatewayV2.GetApis(ctx, &v2.GetApisInput{NextToken: aws.String("x")})
+	assert.ErrorContains(t, err, "BadRequestException")
+	httpStages, err := apiGatewayV2.GetStages(ctx, &v2.GetStagesInput{ApiId: aws.String("http1")})
+	assert.NoError(t, err)
+	assert.Equal(t, "$default", aws.ToString(httpStages.Items[0].StageName))
+	assert.Equal(t, "INFO", string(httpStages.Items[0].DefaultRouteSettings.LoggingLevel))
+
+	logs := cloudwatchlogs.NewFromConfig(cfg, func(o *cloudwatchlogs.Options) { o.BaseEndpoint = endpoint })
+	logGroups, err := logs.DescribeLogGroups(ctx, &cloudwatchlogs.DescribeLogGroupsInput{LogGroupNamePrefix: aws.String("API-Gateway")})
+	assert.NoError(t, err)
+	assert.Len(t, logGroups.LogGroups, 1)
+	assert.Equal(t, "API-Gateway-Execution-Logs_rest1/dev", aws.ToString(logGroups.LogGroups[0].LogGroupName))
+
+	stsClient := sts.NewFromConfig(cfg, func(o *sts.Options) { o.BaseEndpoint = endpoint })
+	identity, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789012", aws.ToString(identity.Account))
+
+	// credentials of an assumed role are served as the account of the role
+	assumed := cfg.Copy()
+	assumed.Region = "eu-west-1"
+	assumed.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(stsClient, "arn:aws:iam::210987654321:role/traceable"))
+	identity, err = sts.NewFromConfig(assumed, func(o *sts.Options) { o.BaseEndpoint = endpoint }).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, "210987654321", aws.ToString(identity.Account))
+	assert.Contains(t, aws.ToString(identity.Arn), "assumed-role/traceable/")
+	_, err = v2.NewFromConfig(assumed, func(o *v2.Options) { o.BaseEndpoint = endpoint }).GetApis(ctx, &v2.GetApisInput{})
+	assert.ErrorContains(t, err, "not allowed")
+
+	_, err = stsClient.AssumeRole(ctx, &sts.AssumeRoleInput{RoleArn: aws.String("arn:aws:iam::999999999999:role/traceable"), RoleSessionName: aws.String("test")})
+	assert.ErrorContains(t, err, "AccessDenied")
+}
+
+func TestLoadFixture(t *testing.T) {
+	fixture, err := LoadFixture("testdata/accounts.yaml")
+	assert.NoError(t, err)
+	assert.Len(t, fixture.Accounts, 2)
+	assert.NotNil(t, fixture.account("123456789012", "us-east-1"))
+	assert.Nil(t, fixture.account("123456789012", "eu-west-1"))
+
+	_, err = LoadFixture("testdata/missing.yaml")
+	assert.Error(t, err)
+}
+
+func TestPage(t *testing.T) {
+	items := []string{"a", "b", "c"}
+	tests := []struct {
+		name     string
+		pageSize int
+		limit    string
+		token    string
+		expected []string
+		next     string
+		err      bool
+	}{
+		{name: "every item", expected: items},
+		{name: "page size", pageSize: 2, expected: []string{"a", "b"}, next: "2"},
+		{name: "last page", pageSize: 2, token: "2", expected: []string{"c"}},
+		{name: "limit below page size", pageSize: 2, limit: "1", token: "1", expected: []string{"b"}, next: "2"},
+		{name: "limit above page size", pageSize: 2, limit: "5", expected: []string{"a", "b"}, next: "2"},
+		{name: "invalid token", token: "x", err: true},
+		{name: "token out of range", token: "4", err: true},
+		{name: "invalid limit", limit: "0", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, next, err := page(items, test.pageSize, test.limit, test.token)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, page)
+			assert.Equal(t, test.next, next)
+		})
+	}
+}
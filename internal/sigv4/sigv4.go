@@ -0,0 +1,29 @@
+// Package sigv4 reads the credential scope of the Signature Version 4 signatures of AWS requests.
+package sigv4
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Scope is the credential scope of a signature.
+type Scope struct {
+	AccessKeyId string
+	Date        string
+	Region      string
+	Service     string
+}
+
+// ParseScope returns the credential scope of a request, empty when it is not signed.
+func ParseScope(r *http.Request) Scope {
+	// AWS4-HMAC-SHA256 Credential=AKID/20240101/us-east-1/apigateway/aws4_request, ...
+	_, credential, found := strings.Cut(r.Header.Get("Authorization"), "Credential=")
+	if !found {
+		return Scope{}
+	}
+	parts := strings.Split(strings.SplitN(credential, ",", 2)[0], "/")
+	if len(parts) < 5 {
+		return Scope{}
+	}
+	return Scope{AccessKeyId: parts[0], Date: parts[1], Region: parts[2], Service: parts[3]}
+}
@@ -0,0 +1,41 @@
+package sigv4
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScope(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		expected      Scope
+	}{
+		{
+			name: "signed",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240101/us-east-1/apigateway/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=abcdef",
+			expected: Scope{AccessKeyId: "AKIDEXAMPLE", Date: "20240101", Region: "us-east-1", Service: "apigateway"},
+		},
+		{
+			name: "unsigned",
+		},
+		{
+			name:          "truncated scope",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240101/us-east-1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodGet, "https://apigateway.us-east-1.amazonaws.com/restapis", nil)
+			assert.NoError(t, err)
+			if test.authorization != "" {
+				r.Header.Set("Authorization", test.authorization)
+			}
+			assert.Equal(t, test.expected, ParseScope(r))
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Access log formats are templates of $context and $stageVariables variables. Customers often
+// leave variables unquoted, so the parser never fails and only splits literals and variables.
+
+var accessLogVariableRoots = []string{"$context.", "$stageVariables."}
+
+type AccessLogNodeKind int
+
+const (
+	LiteralNode AccessLogNodeKind = iota
+	VariableNode
+)
+
+// Position is a location in an access log format, Column counts runes.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+type AccessLogNode struct {
+	Kind AccessLogNodeKind
+	Text string
+	Pos  Position
+	// InString is set for variables enclosed in a JSON string literal
+	InString bool
+}
+
+type AccessLogTemplate struct {
+	Nodes []AccessLogNode
+}
+
+func (t *AccessLogTemplate) Variables() []AccessLogNode {
+	var variables []AccessLogNode
+	for _, node := range t.Nodes {
+		if node.Kind == VariableNode {
+			variables = append(variables, node)
+		}
+	}
+	return variables
+}
+
+func (t *AccessLogTemplate) String() string {
+	var sb strings.Builder
+	for _, node := range t.Nodes {
+		sb.WriteString(node.Text)
+	}
+	return sb.String()
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                tokenizer                                  //
+///////////////////////////////////////////////////////////////////////////////
+
+type accessLogTokenKind int
+
+const (
+	textToken accessLogTokenKind = iota
+	quoteToken
+	delimiterToken
+	variableToken
+)
+
+type accessLogToken struct {
+	kind     accessLogTokenKind
+	text     string
+	pos      Position
+	inString bool
+}
+
+type accessLogTokenizer struct {
+	input    string
+	offset   int
+	pos      Position
+	inString bool
+	tokens   []accessLogToken
+}
+
+func tokenizeAccessLogFormat(format string) []accessLogToken {
+	t := &accessLogTokenizer{
+		input: format,
+		pos:   Position{Line: 1, Column: 1},
+	}
+	textStart := -1
+	var textPos Position
+	flushText := func() {
+		if textStart >= 0 {
+			t.tokens = append(t.tokens, accessLogToken{
+				kind:     textToken,
+				text:     t.input[textStart:t.offset],
+				pos:      textPos,
+				inString: t.inString,
+			})
+			textStart = -1
+		}
+	}
+
+	for t.offset < len(t.input) {
+		c := t.input[t.offset]
+		variableLength := 0
+		if c == '$' {
+			variableLength = t.variableLength()
+		}
+		switch {
+		case variableLength > 0:
+			flushText()
+			t.emit(variableToken, variableLength)
+		case c == '"':
+			flushText()
+			t.emit(quoteToken, 1)
+			t.inString = !t.inString
+		case !t.inString && strings.IndexByte("{}[]:,", c) >= 0:
+			flushText()
+			t.emit(delimiterToken, 1)
+		default:
+			if textStart < 0 {
+				textStart = t.offset
+				textPos = t.pos
+			}
+			// an escaped quote does not end the string
+			if c == '\\' && t.inString && t.offset+1 < len(t.input) {
+				t.advance(1)
+			}
+			_, size := utf8.DecodeRuneInString(t.input[t.offset:])
+			t.advance(size)
+		}
+	}
+	flushText()
+	return t.tokens
+}
+
+func (t *accessLogTokenizer) emit(kind accessLogTokenKind, length int) {
+	t.tokens = append(t.tokens, accessLogToken{
+		kind:     kind,
+		text:     t.input[t.offset : t.offset+length],
+		pos:      t.pos,
+		inString: t.inString,
+	})
+	t.advance(length)
+}
+
+func (t *accessLogTokenizer) advance(length int) {
+	end := t.offset + length
+	for t.offset < end {
+		r, size := utf8.DecodeRuneInString(t.input[t.offset:])
+		if t.offset+size > end {
+			size = end - t.offset
+		}
+		t.offset += size
+		t.pos.Offset = t.offset
+		if r == '\n' {
+			t.pos.Line++
+			t.pos.Column = 1
+		} else {
+			t.pos.Column++
+		}
+	}
+}
+
+// variableLength returns the length of a variable such as
+// $context.authorizer.claims['cognito:groups'], or 0 if there is none.
+func (t *accessLogTokenizer) variableLength() int {
+	rest := t.input[t.offset:]
+	i := 0
+	for _, root := range accessLogVariableRoots {
+		if strings.HasPrefix(rest, root) && nameLength(rest[len(root):]) > 0 {
+			i = len(root) + nameLength(rest[len(root):])
+			break
+		}
+	}
+	if i == 0 {
+		return 0
+	}
+	for i < len(rest) {
+		switch rest[i] {
+		case '.':
+			n := nameLength(rest[i+1:])
+			if n == 0 {
+				return i
+			}
+			i += 1 + n
+		case '[':
+			n := t.indexLength(rest[i:])
+			if n == 0 {
+				return i
+			}
+			i += n
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// indexLength returns the length of a quoted index such as ['key'], double quotes would end
+// an enclosing JSON string.
+func (t *accessLogTokenizer) indexLength(s string) int {
+	if len(s) < 2 || (s[1] != '\'' && (s[1] != '"' || t.inString)) {
+		return 0
+	}
+	end := strings.IndexByte(s[2:], s[1])
+	if end < 0 || 2+end+1 >= len(s) || s[2+end+1] != ']' {
+		return 0
+	}
+	for _, c := range []byte(s[2 : 2+end]) {
+		if c < 0x20 || c == '\\' {
+			return 0
+		}
+	}
+	return 2 + end + 2
+}
+
+func nameLength(s string) int {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return i
+		}
+	}
+	return len(s)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                  parser                                   //
+///////////////////////////////////////////////////////////////////////////////
+
+// ParseAccessLogFormat builds the template for an access log format.
+func ParseAccessLogFormat(format string) *AccessLogTemplate {
+	template := &AccessLogTemplate{}
+	for _, token := range tokenizeAccessLogFormat(format) {
+		if token.kind == variableToken {
+			template.Nodes = append(template.Nodes, AccessLogNode{
+				Kind:     VariableNode,
+				Text:     token.text,
+				Pos:      token.pos,
+				InString: token.inString,
+			})
+			continue
+		}
+		last := len(template.Nodes) - 1
+		if last >= 0 && template.Nodes[last].Kind == LiteralNode {
+			template.Nodes[last].Text += token.text
+			continue
+		}
+		template.Nodes = append(template.Nodes, AccessLogNode{
+			Kind: LiteralNode,
+			Text: token.text,
+			Pos:  token.pos,
+		})
+	}
+	return template
+}
+
+// fixAccessLogFormatMissingQuotes quotes the values holding variables, as a whole so that
+// interpolations like $context.path?$context.stage stay together.
+func fixAccessLogFormatMissingQuotes(format string) string {
+	var sb strings.Builder
+	var bare []accessLogToken
+	afterColon := false
+
+	flushBare := func() {
+		hasVariable := false
+		var content strings.Builder
+		for _, token := range bare {
+			content.WriteString(token.text)
+			hasVariable = hasVariable || token.kind == variableToken
+		}
+		value := content.String()
+		if hasVariable {
+			trimmed := strings.TrimFunc(value, unicode.IsSpace)
+			leading := value[:len(value)-len(strings.TrimLeftFunc(value, unicode.IsSpace))]
+			trailing := value[len(leading)+len(trimmed):]
+			if afterColon {
+				leading = ""
+			}
+			value = leading + `"` + escapeJsonString(trimmed) + `"` + trailing
+		}
+		sb.WriteString(value)
+		bare = nil
+	}
+
+	for _, token := range tokenizeAccessLogFormat(format) {
+		if token.inString || token.kind == quoteToken || token.kind == delimiterToken {
+			if len(bare) > 0 {
+				flushBare()
+			}
+			sb.WriteString(token.text)
+			if !token.inString {
+				afterColon = token.text == ":"
+			}
+			continue
+		}
+		bare = append(bare, token)
+	}
+	if len(bare) > 0 {
+		flushBare()
+	}
+	return sb.String()
+}
+
+func escapeJsonString(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			sb.WriteString(`\\`)
+		case c < 0x20:
+			sb.WriteString(fmt.Sprintf(`\u%04x`, c))
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// AnalyzeAccessLogFormat returns the key of every variable of a JSON access log format and
+// the missing mandatory values. A variable under several keys keeps the key holding it
+// alone, or else the first key, so that stages with the same format agree.
+func AnalyzeAccessLogFormat(format string, mandatoryValues []string) (map[string]string, []string, error) {
+	var parsed map[string]interface{}
+	fixedFormat := fixAccessLogFormatMissingQuotes(format)
+	if err := json.Unmarshal([]byte(fixedFormat), &parsed); err != nil {
+		if err = json.Unmarshal([]byte("{"+fixedFormat+"}"), &parsed); err != nil {
+			return nil, nil, fmt.Errorf("access log format is not JSON parsable: %w", err)
+		}
+	}
+
+	flattened := Flatten(parsed)
+	keys := make([]string, 0, len(flattened))
+	for key := range flattened {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	variableKeys := make(map[string]string)
+	// exact are the variables whose key holds the variable alone
+	exact := make(map[string]bool)
+	for _, key := range keys {
+		valueStr, ok := flattened[key].(string)
+		if !ok {
+			continue
+		}
+		// a value may interpolate several variables, e.g. "$context.path?$context.stage"
+		for _, variable := range ParseAccessLogFormat(valueStr).Variables() {
+			_, found := variableKeys[variable.Text]
+			if isExact := valueStr == variable.Text; !found || (isExact && !exact[variable.Text]) {
+				variableKeys[variable.Text] = key
+				exact[variable.Text] = isExact
+			}
+		}
+	}
+
+	var missingValues []string
+	for _, value := range mandatoryValues {
+		if _, found := variableKeys[value]; !found {
+			missingValues = append(missingValues, value)
+		}
+	}
+	return variableKeys, missingValues, nil
+}
@@ -0,0 +1,96 @@
+package discovery
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAccessLogFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []AccessLogNode
+	}{
+		{
+			name:     "empty format",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name:  "literal only",
+			input: `{"key":"value"}`,
+			expected: []AccessLogNode{
+				{Kind: LiteralNode, Text: `{"key":"value"}`, Pos: Position{Offset: 0, Line: 1, Column: 1}},
+			},
+		},
+		{
+			name:  "quoted and unquoted variables",
+			input: `{"a":"$context.path", "b":$context.status}`,
+			expected: []AccessLogNode{
+				{Kind: LiteralNode, Text: `{"a":"`, Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: VariableNode, Text: `$context.path`, Pos: Position{Offset: 6, Line: 1, Column: 7}, InString: true},
+				{Kind: LiteralNode, Text: `", "b":`, Pos: Position{Offset: 19, Line: 1, Column: 20}},
+				{Kind: VariableNode, Text: `$context.status`, Pos: Position{Offset: 26, Line: 1, Column: 27}},
+				{Kind: LiteralNode, Text: `}`, Pos: Position{Offset: 41, Line: 1, Column: 42}},
+			},
+		},
+		{
+			name:  "interpolation across lines",
+			input: "{\n\"u\":\"$context.path?$stageVariables.v\"}",
+			expected: []AccessLogNode{
+				{Kind: LiteralNode, Text: "{\n\"u\":\"", Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: VariableNode, Text: `$context.path`, Pos: Position{Offset: 7, Line: 2, Column: 6}, InString: true},
+				{Kind: LiteralNode, Text: `?`, Pos: Position{Offset: 20, Line: 2, Column: 19}},
+				{Kind: VariableNode, Text: `$stageVariables.v`, Pos: Position{Offset: 21, Line: 2, Column: 20}, InString: true},
+				{Kind: LiteralNode, Text: `"}`, Pos: Position{Offset: 38, Line: 2, Column: 37}},
+			},
+		},
+		{
+			name:  "bracket index and trailing dot",
+			input: `$context.authorizer.claims['cognito:groups'].$context.x[0]`,
+			expected: []AccessLogNode{
+				{Kind: VariableNode, Text: `$context.authorizer.claims['cognito:groups']`, Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				{Kind: LiteralNode, Text: `.`, Pos: Position{Offset: 44, Line: 1, Column: 45}},
+				{Kind: VariableNode, Text: `$context.x`, Pos: Position{Offset: 45, Line: 1, Column: 46}},
+				{Kind: LiteralNode, Text: `[0]`, Pos: Position{Offset: 55, Line: 1, Column: 56}},
+			},
+		},
+		{
+			name:  "not a variable",
+			input: `$contextual $context. $input.body`,
+			expected: []AccessLogNode{
+				{Kind: LiteralNode, Text: `$contextual $context. $input.body`, Pos: Position{Offset: 0, Line: 1, Column: 1}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, ParseAccessLogFormat(test.input).Nodes)
+		})
+	}
+}
+
+func FuzzParseAccessLogFormat(f *testing.F) {
+	for _, seed := range []string{
+		`{"a":"$context.path", "b":$context.status}`,
+		"{\n\"u\":\"$context.path?$stageVariables.v\"}",
+		`$context.authorizer.claims['cognito:groups'].$context.x[0]`,
+		`{"q":"\"$context.path\"", "é": $context.status}`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, format string) {
+		template := ParseAccessLogFormat(format)
+		assert.Equal(t, format, template.String())
+		offset := 0
+		for _, node := range template.Nodes {
+			assert.Equal(t, offset, node.Pos.Offset)
+			assert.Equal(t, node.Text, format[node.Pos.Offset:node.Pos.Offset+len(node.Text)])
+			assert.Equal(t, strings.Count(format[:offset], "\n")+1, node.Pos.Line)
+			offset += len(node.Text)
+		}
+	})
+}
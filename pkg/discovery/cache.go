@@ -0,0 +1,198 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v1 "github.com/aws/aws-sdk-go-v2/service/apigateway"
+	v2 "github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Cache shares the responses of AWS between discoveries for a TTL, errors are not kept.
+type Cache struct {
+	ttl time.Duration
+	// now is replaced by the tests
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[cacheKey]*cacheEntry
+}
+
+// cacheKey identifies a response by account, region, operation and parameters.
+type cacheKey struct {
+	roleArn   string
+	region    string
+	operation string
+	apiId     string
+	// page is the token of the page of a paginated operation
+	page string
+}
+
+type cacheEntry struct {
+	// done is closed once the response is fetched
+	done    chan struct{}
+	value   any
+	err     error
+	expires time.Time
+}
+
+// NewCache returns a cache keeping responses for ttl.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, now: time.Now, entries: make(map[cacheKey]*cacheEntry)}
+}
+
+// get returns the response of key, calling fetch when it is missing or expired.
+func (c *Cache) get(ctx context.Context, key cacheKey, fetch func() (any, error)) (any, error) {
+	for {
+		c.mu.Lock()
+		entry, found := c.entries[key]
+		if found && isDone(entry) && !c.now().Before(entry.expires) {
+			found = false
+		}
+		if !found {
+			// the responses of accounts no longer read would otherwise be kept for the life
+			// of the process
+			c.evictExpired()
+			entry = &cacheEntry{done: make(chan struct{})}
+			c.entries[key] = entry
+			c.mu.Unlock()
+			return c.fetch(key, entry, fetch)
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		// a fetch canceled by the context of another discovery is tried again with this one
+		if errors.Is(entry.err, context.Canceled) || errors.Is(entry.err, context.DeadlineExceeded) {
+			continue
+		}
+		return entry.value, entry.err
+	}
+}
+
+func (c *Cache) fetch(key cacheKey, entry *cacheEntry, fetch func() (any, error)) (any, error) {
+	entry.value, entry.err = fetch()
+	c.mu.Lock()
+	if entry.err != nil {
+		if c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+	} else {
+		entry.expires = c.now().Add(c.ttl)
+	}
+	c.mu.Unlock()
+	close(entry.done)
+	return entry.value, entry.err
+}
+
+// evictExpired removes the expired responses, c.mu is held by the caller.
+func (c *Cache) evictExpired() {
+	now := c.now()
+	for key, entry := range c.entries {
+		if isDone(entry) && !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func isDone(entry *cacheEntry) bool {
+	select {
+	case <-entry.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func cached[T any](ctx context.Context, cache *Cache, key cacheKey, fetch func() (T, error)) (T, error) {
+	value, err := cache.get(ctx, key, func() (any, error) {
+		return fetch()
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return value.(T), nil
+}
+
+// ClientFactory returns a factory whose clients read AWS through the cache.
+func (c *Cache) ClientFactory(factory ClientFactory) ClientFactory {
+	return func(ctx context.Context, account AccountSpec) (*Clients, error) {
+		clients, err := factory(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		scope := cacheKey{roleArn: account.CrossAccountRoleArn, region: account.Region}
+		cachedClients := &Clients{
+			ApiGateway:   &cachedApiGatewayClient{cache: c, scope: scope, client: clients.ApiGateway},
+			ApiGatewayV2: &cachedApiGatewayV2Client{cache: c, scope: scope, client: clients.ApiGatewayV2},
+		}
+		if clients.Sts != nil {
+			stsScope := scope
+			if account.StsRegion != "" {
+				stsScope.region = account.StsRegion
+			}
+			cachedClients.Sts = &cachedStsClient{cache: c, scope: stsScope, client: clients.Sts}
+		}
+		return cachedClients, nil
+	}
+}
+
+func (scope cacheKey) key(operation string, apiId string, page string) cacheKey {
+	scope.operation, scope.apiId, scope.page = operation, apiId, page
+	return scope
+}
+
+type cachedApiGatewayClient struct {
+	cache  *Cache
+	scope  cacheKey
+	client AwsApiGatewayClient
+}
+
+func (c *cachedApiGatewayClient) GetRestApis(ctx context.Context, params *v1.GetRestApisInput, optFns ...func(*v1.Options)) (*v1.GetRestApisOutput, error) {
+	return cached(ctx, c.cache, c.scope.key("GetRestApis", "", aws.ToString(params.Position)), func() (*v1.GetRestApisOutput, error) {
+		return c.client.GetRestApis(ctx, params, optFns...)
+	})
+}
+
+func (c *cachedApiGatewayClient) GetStages(ctx context.Context, params *v1.GetStagesInput, optFns ...func(*v1.Options)) (*v1.GetStagesOutput, error) {
+	return cached(ctx, c.cache, c.scope.key("GetStages", aws.ToString(params.RestApiId), ""), func() (*v1.GetStagesOutput, error) {
+		return c.client.GetStages(ctx, params, optFns...)
+	})
+}
+
+type cachedApiGatewayV2Client struct {
+	cache  *Cache
+	scope  cacheKey
+	client AwsApiGatewayV2Client
+}
+
+func (c *cachedApiGatewayV2Client) GetApis(ctx context.Context, params *v2.GetApisInput, optFns ...func(*v2.Options)) (*v2.GetApisOutput, error) {
+	return cached(ctx, c.cache, c.scope.key("GetApis", "", aws.ToString(params.NextToken)), func() (*v2.GetApisOutput, error) {
+		return c.client.GetApis(ctx, params, optFns...)
+	})
+}
+
+func (c *cachedApiGatewayV2Client) GetStages(ctx context.Context, params *v2.GetStagesInput, optFns ...func(*v2.Options)) (*v2.GetStagesOutput, error) {
+	return cached(ctx, c.cache, c.scope.key("GetStagesV2", aws.ToString(params.ApiId), aws.ToString(params.NextToken)), func() (*v2.GetStagesOutput, error) {
+		return c.client.GetStages(ctx, params, optFns...)
+	})
+}
+
+type cachedStsClient struct {
+	cache  *Cache
+	scope  cacheKey
+	client AwsStsClient
+}
+
+func (c *cachedStsClient) GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	return cached(ctx, c.cache, c.scope.key("GetCallerIdentity", "", ""), func() (*sts.GetCallerIdentityOutput, error) {
+		return c.client.GetCallerIdentity(ctx, params, optFns...)
+	})
+}
@@ -0,0 +1,196 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v1 "github.com/aws/aws-sdk-go-v2/service/apigateway"
+	v1types "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
+	v2 "github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewCache(time.Minute)
+	cache.now = func() time.Time { return now }
+	key := cacheKey{region: "us-east-1", operation: "GetRestApis"}
+	var fetches int
+	fetch := func() (any, error) {
+		fetches++
+		return fetches, nil
+	}
+
+	value, err := cache.get(context.Background(), key, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	// responses are kept for the TTL
+	now = now.Add(59 * time.Second)
+	value, _ = cache.get(context.Background(), key, fetch)
+	assert.Equal(t, 1, value)
+	now = now.Add(time.Second)
+	value, _ = cache.get(context.Background(), key, fetch)
+	assert.Equal(t, 2, value)
+	// other keys are fetched on their own
+	value, _ = cache.get(context.Background(), cacheKey{region: "eu-west-1", operation: "GetRestApis"}, fetch)
+	assert.Equal(t, 3, value)
+
+	// errors are not kept
+	errorKey := cacheKey{region: "us-east-1", operation: "GetApis"}
+	_, err = cache.get(context.Background(), errorKey, func() (any, error) { return nil, errors.New("throttled") })
+	assert.EqualError(t, err, "throttled")
+	value, err = cache.get(context.Background(), errorKey, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, value)
+}
+
+func TestCacheEviction(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewCache(time.Minute)
+	cache.now = func() time.Time { return now }
+	fetch := func() (any, error) { return "response", nil }
+
+	for _, region := range []string{"us-east-1", "eu-west-1"} {
+		_, err := cache.get(context.Background(), cacheKey{region: region, operation: "GetRestApis"}, fetch)
+		assert.NoError(t, err)
+	}
+	assert.Len(t, cache.entries, 2)
+
+	// expired responses are removed when another one is fetched, whatever their key
+	now = now.Add(time.Minute)
+	_, err := cache.get(context.Background(), cacheKey{region: "ap-south-1", operation: "GetRestApis"}, fetch)
+	assert.NoError(t, err)
+	assert.Len(t, cache.entries, 1)
+	assert.Contains(t, cache.entries, cacheKey{region: "ap-south-1", operation: "GetRestApis"})
+}
+
+func TestCacheSingleFetch(t *testing.T) {
+	cache := NewCache(time.Minute)
+	key := cacheKey{region: "us-east-1", operation: "GetStages", apiId: "rest1"}
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	fetch := func() (any, error) {
+		fetches.Add(1)
+		<-release
+		return "stages", nil
+	}
+
+	var wg sync.WaitGroup
+	values := make([]any, 10)
+	for i := range values {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], _ = cache.get(context.Background(), key, fetch)
+		}()
+	}
+	for fetches.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// waiters give up with their context while the fetch goes on
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := cache.get(ctx, key, fetch)
+	assert.ErrorIs(t, err, context.Canceled)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), fetches.Load())
+	for _, value := range values {
+		assert.Equal(t, "stages", value)
+	}
+
+	// a fetch canceled by its caller is tried again by the waiters
+	retryKey := cacheKey{region: "us-east-1", operation: "GetApis"}
+	started := make(chan struct{})
+	go func() {
+		_, _ = cache.get(context.Background(), retryKey, func() (any, error) {
+			close(started)
+			time.Sleep(10 * time.Millisecond)
+			return nil, context.Canceled
+		})
+	}()
+	<-started
+	value, err := cache.get(context.Background(), retryKey, func() (any, error) { return "apis", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "apis", value)
+}
+
+// countingClients count the requests reaching AWS.
+type countingClients struct {
+	requests map[string]int
+}
+
+func (c *countingClients) GetRestApis(_ context.Context, params *v1.GetRestApisInput, _ ...func(*v1.Options)) (*v1.GetRestApisOutput, error) {
+	c.requests["GetRestApis "+aws.ToString(params.Position)]++
+	return &v1.GetRestApisOutput{Items: []v1types.RestApi{{Id: aws.String("rest1")}}}, nil
+}
+
+func (c *countingClients) GetStages(_ context.Context, params *v1.GetStagesInput, _ ...func(*v1.Options)) (*v1.GetStagesOutput, error) {
+	c.requests["GetStages "+aws.ToString(params.RestApiId)]++
+	return &v1.GetStagesOutput{}, nil
+}
+
+func (c *countingClients) GetApis(_ context.Context, _ *v2.GetApisInput, _ ...func(*v2.Options)) (*v2.GetApisOutput, error) {
+	c.requests["GetApis"]++
+	return &v2.GetApisOutput{}, nil
+}
+
+func (c *countingClients) GetCallerIdentity(_ context.Context, _ *sts.GetCallerIdentityInput, _ ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	c.requests["GetCallerIdentity"]++
+	return &sts.GetCallerIdentityOutput{Account: aws.String("123456789012")}, nil
+}
+
+func TestCacheClientFactory(t *testing.T) {
+	counts := make(map[string]*countingClients)
+	factory := NewCache(time.Minute).ClientFactory(func(_ context.Context, account AccountSpec) (*Clients, error) {
+		if account.Region == "ap-east-1" {
+			return nil, errors.New("no credentials")
+		}
+		clients := &countingClients{requests: make(map[string]int)}
+		counts[account.Region+" "+account.CrossAccountRoleArn] = clients
+		return &Clients{ApiGateway: clients, ApiGatewayV2: &countingV2Clients{clients}, Sts: clients}, nil
+	})
+	role := "arn:aws:iam::123456789012:role/traceable"
+	read := func(account AccountSpec) {
+		clients, err := factory(context.Background(), account)
+		assert.NoError(t, err)
+		ctx := context.Background()
+		_, _ = clients.ApiGateway.GetRestApis(ctx, &v1.GetRestApisInput{})
+		_, _ = clients.ApiGateway.GetStages(ctx, &v1.GetStagesInput{RestApiId: aws.String("rest1")})
+		_, _ = clients.ApiGatewayV2.GetApis(ctx, &v2.GetApisInput{})
+		_, _ = clients.Sts.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	}
+
+	read(AccountSpec{Region: "us-east-1", CrossAccountRoleArn: role})
+	first := counts["us-east-1 "+role]
+	read(AccountSpec{Region: "us-east-1", CrossAccountRoleArn: role})
+	// the second account got new clients, whose requests were answered by the cache
+	assert.Equal(t, map[string]int{}, counts["us-east-1 "+role].requests)
+	assert.Equal(t, map[string]int{"GetRestApis ": 1, "GetStages rest1": 1, "GetApis": 1, "GetCallerIdentity": 1}, first.requests)
+
+	// accounts read in another region or with other credentials are not shared
+	read(AccountSpec{Region: "eu-west-1", CrossAccountRoleArn: role})
+	read(AccountSpec{Region: "us-east-1"})
+	assert.Len(t, counts["eu-west-1 "+role].requests, 4)
+	assert.Len(t, counts["us-east-1 "].requests, 4)
+
+	_, err := factory(context.Background(), AccountSpec{Region: "ap-east-1"})
+	assert.EqualError(t, err, "no credentials")
+}
+
+// countingV2Clients are the API Gateway V2 side of countingClients, whose GetStages is the
+// one of API Gateway.
+type countingV2Clients struct {
+	*countingClients
+}
+
+func (c *countingV2Clients) GetStages(_ context.Context, params *v2.GetStagesInput, _ ...func(*v2.Options)) (*v2.GetStagesOutput, error) {
+	c.requests["GetStagesV2 "+aws.ToString(params.ApiId)]++
+	return &v2.GetStagesOutput{}, nil
+}
@@ -0,0 +1,251 @@
+// Package cassette records the sanitized responses of AWS to a file and replays them, so that
+// the behavior of a real account can be reproduced offline.
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/internal/sigv4"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"gopkg.in/yaml.v3"
+)
+
+// Cassette is a list of interactions with AWS in the order they were recorded.
+type Cassette struct {
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+type Interaction struct {
+	Request  Request  `yaml:"request"`
+	Response Response `yaml:"response"`
+}
+
+// Request identifies a call independently of the endpoint it was sent to.
+type Request struct {
+	Service string `yaml:"service"`
+	Region  string `yaml:"region"`
+	Method  string `yaml:"method"`
+	// Path includes the query string
+	Path string `yaml:"path"`
+	// Target is the X-Amz-Target header of the JSON protocols, such as CloudWatch Logs
+	Target string `yaml:"target,omitempty"`
+	Body   string `yaml:"body,omitempty"`
+}
+
+type Response struct {
+	Status      int    `yaml:"status"`
+	ContentType string `yaml:"content_type,omitempty"`
+	// ErrorType is the X-Amzn-Errortype header, API Gateway errors are told apart by it
+	ErrorType string `yaml:"error_type,omitempty"`
+	Body      string `yaml:"body,omitempty"`
+}
+
+// Load reads a cassette, failing on unknown keys.
+func Load(cassettePath string) (*Cassette, error) {
+	file, err := os.Open(cassettePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	cassette := &Cassette{}
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cassette); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("parsing %s: %w", cassettePath, err)
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette, replacing the file only once it is complete.
+func (c *Cassette) Save(cassettePath string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cassettePath), 0o755); err != nil {
+		return err
+	}
+	tmpPath := cassettePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, cassettePath)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                 recorder                                  //
+///////////////////////////////////////////////////////////////////////////////
+
+// Recorder appends the sanitized interactions with AWS to a cassette file, rewritten after
+// every interaction so that a failed run still leaves them.
+type Recorder struct {
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder returns a recorder overwriting the cassette at cassettePath.
+func NewRecorder(cassettePath string) *Recorder {
+	return &Recorder{path: cassettePath}
+}
+
+// Client returns an HTTP client sending the requests with next and recording them.
+func (r *Recorder) Client(next aws.HTTPClient) aws.HTTPClient {
+	return &recordingClient{recorder: r, next: next}
+}
+
+type recordingClient struct {
+	recorder *Recorder
+	next     aws.HTTPClient
+}
+
+func (c *recordingClient) Do(req *http.Request) (*http.Response, error) {
+	request, err := readRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	err = c.recorder.record(Interaction{
+		Request: sanitizeRequest(request),
+		Response: Response{
+			Status:      resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			ErrorType:   resp.Header.Get("X-Amzn-Errortype"),
+			Body:        Sanitize(string(body)),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (r *Recorder) record(interaction Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	if err := r.cassette.Save(r.path); err != nil {
+		return fmt.Errorf("recording to %s: %w", r.path, err)
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                 replayer                                  //
+///////////////////////////////////////////////////////////////////////////////
+
+// Replayer answers the requests from a cassette, identical requests get their recorded
+// responses in order and the last one once they are used up.
+type Replayer struct {
+	path     string
+	mu       sync.Mutex
+	cassette *Cassette
+	// replayed counts the responses served for each request
+	replayed map[Request]int
+}
+
+var _ aws.HTTPClient = (*Replayer)(nil)
+
+func NewReplayer(cassettePath string) (*Replayer, error) {
+	cassette, err := Load(cassettePath)
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{path: cassettePath, cassette: cassette, replayed: make(map[Request]int)}, nil
+}
+
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	request, err := readRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	request = sanitizeRequest(request)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matches []Response
+	for _, interaction := range r.cassette.Interactions {
+		if interaction.Request == request {
+			matches = append(matches, interaction.Response)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, &MissingResponseError{path: r.path, request: request}
+	}
+	response := matches[min(r.replayed[request], len(matches)-1)]
+	r.replayed[request]++
+
+	header := http.Header{}
+	if response.ContentType != "" {
+		header.Set("Content-Type", response.ContentType)
+	}
+	if response.ErrorType != "" {
+		header.Set("X-Amzn-Errortype", response.ErrorType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", response.Status, http.StatusText(response.Status)),
+		StatusCode:    response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(response.Body)),
+		ContentLength: int64(len(response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// MissingResponseError is returned for requests the cassette has no response to.
+type MissingResponseError struct {
+	path    string
+	request Request
+}
+
+func (e *MissingResponseError) Error() string {
+	return fmt.Sprintf("%s has no response to %s %s %s%s in %s", e.path, e.request.Service, e.request.Method,
+		e.request.Path, e.request.Target, e.request.Region)
+}
+
+func (e *MissingResponseError) RetryableError() bool {
+	return false
+}
+
+// readRequest reads the body of a request, leaving it in place to be sent.
+func readRequest(req *http.Request) (Request, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return Request{}, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	request := Request{
+		Method: req.Method,
+		Path:   req.URL.RequestURI(),
+		Target: req.Header.Get("X-Amz-Target"),
+		Body:   string(body),
+	}
+	scope := sigv4.ParseScope(req)
+	request.Region, request.Service = scope.Region, scope.Service
+	return request, nil
+}
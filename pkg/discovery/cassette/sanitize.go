@@ -0,0 +1,203 @@
+package cassette
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Kind is the prefix customer identifiers of a kind are anonymized to.
+type Kind string
+
+const (
+	KindAccountId Kind = "000000"
+	KindApiId     Kind = "api"
+	KindApiName   Kind = "name-"
+	KindStageName Kind = "stage-"
+	KindRoleName  Kind = "role-"
+	KindUserName  Kind = "user-"
+	KindLogGroup  Kind = "log-group-"
+)
+
+var (
+	// accessKeyPattern matches the IDs of access keys, users and roles
+	accessKeyPattern = regexp.MustCompile(`\b(AKIA|ASIA|AIDA|AROA|ANPA|AGPA)[A-Z0-9]{12,}`)
+	secretPattern    = regexp.MustCompile(`<(SecretAccessKey|SessionToken)>[^<]*</(SecretAccessKey|SessionToken)>`)
+	// sessionNamePattern matches the role session names generated by the SDK from the time
+	sessionNamePattern = regexp.MustCompile(`RoleSessionName=aws-go-sdk-[0-9]+`)
+	// arnPattern matches the partition, service, region, account ID and resource of ARNs
+	arnPattern = regexp.MustCompile(`arn:(aws|aws-cn|aws-us-gov):([a-z0-9-]+):([a-z0-9-]*):([0-9]{12})?:([^\s"'<>,\\]+)`)
+	// hostPattern matches the API ID of execute-api hostnames
+	hostPattern = regexp.MustCompile(`\b([a-z0-9]+)\.execute-api\.`)
+	// fieldPattern matches the JSON fields and XML elements holding identifiers
+	fieldPattern = regexp.MustCompile(`("(id|apiId|restApiId|name|stageName|accountId)"\s*:\s*")([^"]*)(")|(<Account>)([0-9]{12})(</Account>)`)
+	// pathPattern matches the API IDs and stage names of the paths of API Gateway
+	pathPattern = regexp.MustCompile(`/(restapis|apis|stages)/([^/?]+)`)
+	// specifiedPattern matches the IDs of errors such as specified 123456789012:abcdef1234
+	specifiedPattern = regexp.MustCompile(`(specified )([0-9]{12}):([A-Za-z0-9]+)`)
+	// executionLogGroupPattern matches the execution log groups of REST and WebSocket APIs
+	executionLogGroupPattern = regexp.MustCompile(`^(API-Gateway-Execution-Logs_|/aws/apigateway/)([^/]+)/([^/:]+)$`)
+)
+
+var fieldKinds = map[string]Kind{
+	"id":        KindApiId,
+	"apiId":     KindApiId,
+	"restApiId": KindApiId,
+	"name":      KindApiName,
+	"stageName": KindStageName,
+	"accountId": KindAccountId,
+}
+
+// sanitizeRequest sanitizes the fields of a request, they are compared as is when replaying.
+func sanitizeRequest(request Request) Request {
+	request.Path = Sanitize(request.Path)
+	// the values of form bodies, such as the role ARN of AssumeRole, are URL encoded
+	if values, err := url.ParseQuery(request.Body); err == nil && values.Has("Action") {
+		for key := range values {
+			for i, value := range values[key] {
+				values[key][i] = Sanitize(value)
+			}
+		}
+		request.Body = values.Encode()
+	} else {
+		request.Body = Sanitize(request.Body)
+	}
+	request.Body = sessionNamePattern.ReplaceAllString(request.Body, "RoleSessionName=aws-go-sdk-0")
+	return request
+}
+
+// Sanitize returns the text with the credentials redacted and the customer identifiers
+// anonymized, always to the same value so that a cassette stays consistent.
+func Sanitize(text string) string {
+	text = secretPattern.ReplaceAllString(text, "<$1>REDACTED</$2>")
+	text = accessKeyPattern.ReplaceAllString(text, "${1}REDACTED")
+	text = arnPattern.ReplaceAllStringFunc(text, func(arn string) string {
+		parts := arnPattern.FindStringSubmatch(arn)
+		accountId := parts[4]
+		if accountId != "" {
+			accountId = Anonymize(KindAccountId, accountId)
+		}
+		return fmt.Sprintf("arn:%s:%s:%s:%s:%s", parts[1], parts[2], parts[3], accountId, anonymizeResource(parts[2], parts[5]))
+	})
+	text = hostPattern.ReplaceAllStringFunc(text, func(host string) string {
+		apiId, rest, _ := strings.Cut(host, ".")
+		return Anonymize(KindApiId, apiId) + "." + rest
+	})
+	text = fieldPattern.ReplaceAllStringFunc(text, func(field string) string {
+		parts := fieldPattern.FindStringSubmatch(field)
+		if parts[5] != "" {
+			return parts[5] + Anonymize(KindAccountId, parts[6]) + parts[7]
+		}
+		return parts[1] + Anonymize(fieldKinds[parts[2]], parts[3]) + parts[4]
+	})
+	text = anonymizePath(text)
+	return specifiedPattern.ReplaceAllStringFunc(text, func(specified string) string {
+		parts := specifiedPattern.FindStringSubmatch(specified)
+		return parts[1] + Anonymize(KindAccountId, parts[2]) + ":" + Anonymize(KindApiId, parts[3])
+	})
+}
+
+// anonymizeResource anonymizes the resource part of an ARN of service.
+func anonymizeResource(service string, resource string) string {
+	switch service {
+	case "iam":
+		// role/path/name, user/name
+		parts := strings.Split(resource, "/")
+		kind := map[string]Kind{"role": KindRoleName, "user": KindUserName}[parts[0]]
+		if kind == "" {
+			return resource
+		}
+		for i := 1; i < len(parts); i++ {
+			parts[i] = Anonymize(kind, parts[i])
+		}
+		return strings.Join(parts, "/")
+	case "sts":
+		// assumed-role/name/session, the session names are not customer identifiers
+		parts := strings.Split(resource, "/")
+		if parts[0] == "assumed-role" && len(parts) > 1 {
+			parts[1] = Anonymize(KindRoleName, parts[1])
+		}
+		return strings.Join(parts, "/")
+	case "logs":
+		// log-group:name, log-group:name:*
+		name, found := strings.CutPrefix(resource, "log-group:")
+		if !found {
+			return resource
+		}
+		name, suffix, _ := strings.Cut(name, ":")
+		if suffix != "" {
+			suffix = ":" + suffix
+		}
+		return "log-group:" + anonymizeLogGroup(name) + suffix
+	case "execute-api":
+		// apiId/stage/method/path
+		parts := strings.SplitN(resource, "/", 3)
+		parts[0] = Anonymize(KindApiId, parts[0])
+		if len(parts) > 1 {
+			parts[1] = Anonymize(KindStageName, parts[1])
+		}
+		return strings.Join(parts, "/")
+	case "apigateway":
+		// /restapis/apiId/stages/stage
+		return anonymizePath(resource)
+	}
+	return resource
+}
+
+func anonymizePath(text string) string {
+	return pathPattern.ReplaceAllStringFunc(text, func(path string) string {
+		parts := pathPattern.FindStringSubmatch(path)
+		kind := KindApiId
+		if parts[1] == "stages" {
+			kind = KindStageName
+		}
+		return "/" + parts[1] + "/" + Anonymize(kind, parts[2])
+	})
+}
+
+// anonymizeLogGroup keeps the execution log group names discovery expects.
+func anonymizeLogGroup(name string) string {
+	if parts := executionLogGroupPattern.FindStringSubmatch(name); parts != nil {
+		return parts[1] + Anonymize(KindApiId, parts[2]) + "/" + Anonymize(KindStageName, parts[3])
+	}
+	return Anonymize(KindLogGroup, name)
+}
+
+// Anonymize returns the value an identifier of kind is replaced with in cassettes, anonymized
+// values and stage names such as $default are left as is.
+func Anonymize(kind Kind, value string) string {
+	if value == "" || value == "*" || strings.HasPrefix(value, "$") || strings.HasPrefix(value, "%24") ||
+		isAnonymized(kind, value) {
+		return value
+	}
+	hash := fnv.New32a()
+	// account IDs are hashed alone, to keep the anonymized IDs of earlier cassettes
+	if kind != KindAccountId {
+		_, _ = hash.Write([]byte(kind))
+	}
+	_, _ = hash.Write([]byte(value))
+	if kind == KindApiId {
+		// API IDs keep the 10 characters of API Gateway
+		return fmt.Sprintf("%s%07d", kind, hash.Sum32()%10000000)
+	}
+	return fmt.Sprintf("%s%06d", kind, hash.Sum32()%1000000)
+}
+
+func AnonymizeAccountId(accountId string) string {
+	return Anonymize(KindAccountId, accountId)
+}
+
+func isAnonymized(kind Kind, value string) bool {
+	digits, found := strings.CutPrefix(value, string(kind))
+	if !found || digits == "" {
+		return false
+	}
+	for _, digit := range digits {
+		if digit < '0' || digit > '9' {
+			return false
+		}
+	}
+	return true
+}
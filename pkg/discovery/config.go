@@ -0,0 +1,130 @@
+package discovery
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Config holds the arguments of discovery as written in Terraform or in a file.
+type Config struct {
+	Identifier              string            `json:"identifier" yaml:"identifier"`
+	IgnoreAccessLogSettings bool              `json:"ignore_access_log_settings" yaml:"ignore_access_log_settings"`
+	StrictLogGroupFormat    bool              `json:"strict_log_group_format" yaml:"strict_log_group_format"`
+	Timeout                 string            `json:"timeout" yaml:"timeout"`
+	FindingSeverity         map[string]string `json:"finding_severity,omitempty" yaml:"finding_severity,omitempty"`
+	FailOn                  string            `json:"fail_on,omitempty" yaml:"fail_on,omitempty"`
+	FailOnUnmatched         bool              `json:"fail_on_unmatched,omitempty" yaml:"fail_on_unmatched,omitempty"`
+	ReportPath              string            `json:"report_path,omitempty" yaml:"report_path,omitempty"`
+	ReportFormat            string            `json:"report_format,omitempty" yaml:"report_format,omitempty"`
+	Accounts                []AccountConfig   `json:"accounts" yaml:"accounts"`
+}
+
+type AccountConfig struct {
+	Region      string   `json:"region" yaml:"region"`
+	IncludeApis []string `json:"include_apis,omitempty" yaml:"include_apis,omitempty"`
+	ExcludeApis []string `json:"exclude_apis,omitempty" yaml:"exclude_apis,omitempty"`
+	// Deprecated: ApiList and Exclude are replaced by IncludeApis and ExcludeApis
+	ApiList             []string `json:"api_list" yaml:"api_list"`
+	CrossAccountRoleArn string   `json:"cross_account_role_arn" yaml:"cross_account_role_arn"`
+	StsRegion           string   `json:"sts_region,omitempty" yaml:"sts_region,omitempty"`
+	Exclude             bool     `json:"exclude" yaml:"exclude"`
+}
+
+// Validate checks an account read from a file or an import ID as the schema does.
+func (a AccountConfig) Validate() error {
+	if err := ValidateRegion(a.Region); err != nil {
+		return fmt.Errorf("region %w", err)
+	}
+	if a.CrossAccountRoleArn != "" {
+		if err := ValidateRoleArn(a.CrossAccountRoleArn); err != nil {
+			return fmt.Errorf("cross_account_role_arn %w", err)
+		}
+	}
+	if a.StsRegion != "" {
+		if err := ValidateRegion(a.StsRegion); err != nil {
+			return fmt.Errorf("sts_region %w", err)
+		}
+	}
+	lists := []struct {
+		name     string
+		entries  []string
+		patterns bool
+	}{
+		{"include_apis", a.IncludeApis, true},
+		{"exclude_apis", a.ExcludeApis, true},
+		{"api_list", a.ApiList, false},
+	}
+	for _, list := range lists {
+		for _, entry := range list.entries {
+			if err := ValidateSelectorEntry(entry, list.patterns); err != nil {
+				return fmt.Errorf("%s %w", list.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Specs returns the accounts to discover.
+func (c *Config) Specs() []AccountSpec {
+	specs := make([]AccountSpec, 0, len(c.Accounts))
+	for _, account := range c.Accounts {
+		specs = append(specs, AccountSpec{
+			Region:              account.Region,
+			CrossAccountRoleArn: account.CrossAccountRoleArn,
+			StsRegion:           account.StsRegion,
+			Selector: Selector{
+				IncludeApis: account.IncludeApis,
+				ExcludeApis: account.ExcludeApis,
+				ApiList:     account.ApiList,
+				Exclude:     account.Exclude,
+			},
+		})
+	}
+	return specs
+}
+
+// Validate checks the options read from a file or an import ID as the schema does.
+func (c *Config) Validate() error {
+	if c.Timeout != "" {
+		if err := ValidateTimeout(c.Timeout); err != nil {
+			return fmt.Errorf("timeout %w", err)
+		}
+	}
+	for code, severity := range c.FindingSeverity {
+		if !slices.Contains(FindingCodes(), code) || !slices.Contains(FindingSeverities, severity) {
+			return fmt.Errorf("finding_severity %q = %q is not a finding code and one of %s",
+				code, severity, StringFromArray(FindingSeverities))
+		}
+	}
+	if c.FailOn != "" && !slices.Contains(FailOnModes, c.FailOn) {
+		return fmt.Errorf("fail_on %q is not one of %s", c.FailOn, StringFromArray(FailOnModes))
+	}
+	if c.ReportFormat != "" && !slices.Contains(ReportFormats, c.ReportFormat) {
+		return fmt.Errorf("report_format %q is not one of %s", c.ReportFormat, StringFromArray(ReportFormats))
+	}
+	return nil
+}
+
+// Options returns the options of discovery, the report settings are left to the caller.
+func (c *Config) Options() (Options, error) {
+	opts := Options{
+		IgnoreAccessLogSettings: c.IgnoreAccessLogSettings,
+		StrictLogGroupFormat:    c.StrictLogGroupFormat,
+		FailOn:                  FailOn(c.FailOn),
+		FailOnUnmatched:         c.FailOnUnmatched,
+	}
+	if c.Timeout != "" {
+		if err := ValidateTimeout(c.Timeout); err != nil {
+			return opts, err
+		}
+		opts.Timeout, _ = time.ParseDuration(c.Timeout)
+	}
+	if c.FindingSeverity != nil {
+		opts.FindingSeverity = make(map[string]FindingSeverity, len(c.FindingSeverity))
+		for code, severity := range c.FindingSeverity {
+			opts.FindingSeverity[code] = FindingSeverity(severity)
+		}
+	}
+	return opts, nil
+}
@@ -0,0 +1,732 @@
+// Package discovery finds the log groups carrying the traffic of API Gateway stages and
+// checks their logging settings.
+package discovery
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v1 "github.com/aws/aws-sdk-go-v2/service/apigateway"
+	v1types "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
+	v2 "github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	v2types "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// AccountSpec is an account to discover, read in a region and through an optional role.
+type AccountSpec struct {
+	Region              string
+	CrossAccountRoleArn string
+	// StsRegion is the region of STS, Options.StsRegion or Region when empty
+	StsRegion string
+	Selector  Selector
+}
+
+// Selector selects stages with api and api/stage entries, the api part matching the ID or
+// the name of an api, both parts with the * and ? wildcards. The most specific entry matching
+// a stage decides, and stages matching no entry are only checked when IncludeApis is empty.
+type Selector struct {
+	IncludeApis []string
+	ExcludeApis []string
+	// Deprecated: ApiList selects api IDs and apiId/stageName entries, or excludes them with Exclude.
+	ApiList []string
+	Exclude bool
+}
+
+func (s Selector) legacy() bool {
+	return len(s.ApiList) > 0 || s.Exclude
+}
+
+// Options apply to every account of a discovery.
+type Options struct {
+	IgnoreAccessLogSettings bool
+	// StrictLogGroupFormat leaves out log groups whose stages write different access log keys
+	StrictLogGroupFormat bool
+	// Timeout bounds the whole discovery, 0 for none
+	Timeout time.Duration
+	// FindingSeverity overrides the severity of findings by code
+	FindingSeverity map[string]FindingSeverity
+	// FailOn is only evaluated by callers, see FailOn.Fails
+	FailOn FailOn
+	// Partition overrides the partition of the regions of the accounts
+	Partition Partition
+	// StsRegion is the STS region of the accounts without one
+	StsRegion string
+	// FailOnUnmatched reports selector entries matching no api or stage as errors
+	FailOnUnmatched bool
+	// ClientFactory defaults to DefaultClientFactory
+	ClientFactory ClientFactory
+}
+
+// SpecError is returned for an account spec that can't be discovered.
+type SpecError struct {
+	Index   int
+	Message string
+}
+
+func (e *SpecError) Error() string {
+	return fmt.Sprintf("account %d: %s", e.Index, e.Message)
+}
+
+// Discover checks the selected stages of each account and returns their log groups and
+// findings, accounts failing with AWS errors are listed in Result.FailedAccounts.
+func Discover(ctx context.Context, accounts []AccountSpec, opts Options) (*Result, error) {
+	if opts.Partition != "" && !slices.Contains(Partitions, string(opts.Partition)) {
+		return nil, fmt.Errorf("partition %q is not one of %s", opts.Partition, StringFromArray(Partitions))
+	}
+	for i, account := range accounts {
+		if account.Region == "" {
+			return nil, &SpecError{Index: i, Message: "region cannot be empty"}
+		}
+		// STS and roles can only be used by credentials of their partition
+		partition := accountPartition(account, opts.Partition)
+		if stsRegion := cmp.Or(account.StsRegion, opts.StsRegion); stsRegion != "" && PartitionOfRegion(stsRegion) != partition {
+			return nil, &SpecError{Index: i, Message: fmt.Sprintf("sts_region %s is in the %s partition, the account is in %s",
+				stsRegion, PartitionOfRegion(stsRegion), partition)}
+		}
+		if account.CrossAccountRoleArn != "" {
+			if roleArn, err := ParseArn(account.CrossAccountRoleArn); err == nil && Partition(roleArn.Partition) != partition {
+				return nil, &SpecError{Index: i, Message: fmt.Sprintf("cross_account_role_arn is in the %s partition, the account is in %s, such as %s",
+					roleArn.Partition, partition, RoleArn(partition, roleArn.AccountID, strings.TrimPrefix(roleArn.Resource, "role/")))}
+			}
+		}
+		selector := account.Selector
+		if selector.legacy() && (len(selector.IncludeApis) > 0 || len(selector.ExcludeApis) > 0) {
+			return nil, &SpecError{Index: i, Message: "api_list and exclude can't be combined with include_apis and exclude_apis"}
+		}
+		if !selector.legacy() && len(selector.IncludeApis) == 0 && len(selector.ExcludeApis) == 0 {
+			return nil, &SpecError{Index: i, Message: "include_apis or exclude_apis must be set"}
+		}
+	}
+	clientFactory := opts.ClientFactory
+	if clientFactory == nil {
+		clientFactory = DefaultClientFactory
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	collector := newFindingCollector()
+	collector.setFindingSeverity(opts.FindingSeverity)
+	logGroupNames := make([]string, 0)
+	stageInventory := &inventory{}
+	tflog.Info(ctx, "Initializing provider")
+	for i, account := range accounts {
+		tflog.Debug(ctx, "fetching details of account", map[string]interface{}{
+			"region":                 account.Region,
+			"include_apis":           account.Selector.IncludeApis,
+			"exclude_apis":           account.Selector.ExcludeApis,
+			"api_list":               account.Selector.ApiList,
+			"cross_account_role_arn": account.CrossAccountRoleArn,
+			"exclude":                account.Selector.Exclude,
+		})
+
+		sel := newSelection(account.Selector)
+		collector.setAccount(findingAccount{Index: i, Region: account.Region, Partition: accountPartition(account, opts.Partition)}, sel)
+		if account.StsRegion == "" {
+			account.StsRegion = opts.StsRegion
+		}
+		clients, err := clientFactory(ctx, account)
+		if err != nil {
+			tflog.Error(ctx, fmt.Sprintf("Error creating AWS clients: %v", err))
+			collector.addAccountError(err.Error())
+			continue
+		}
+
+		collector.account.AccountId = getAccountId(ctx, clients.Sts, account.CrossAccountRoleArn)
+		stageInventory.setAccount(collector.account)
+		logGroupNames = append(logGroupNames,
+			getLogGroupNames(ctx, sel, opts.IgnoreAccessLogSettings, opts.StrictLogGroupFormat, opts.FailOnUnmatched, clients.provider(),
+				stageInventory, collector)...)
+	}
+
+	// results are sorted so that plans only show actual changes
+	sort.Strings(logGroupNames)
+	stageInventory.sort()
+	return newResult(logGroupNames, stageInventory, collector.failedAccounts(accounts), collector), nil
+}
+
+// getAccountId falls back to the account of the role when the caller identity can't be read.
+func getAccountId(ctx context.Context, stsClient AwsStsClient, crossAccRoleArn string) string {
+	if stsClient != nil {
+		identity, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+		if err == nil {
+			return aws.ToString(identity.Account)
+		}
+		tflog.Warn(ctx, fmt.Sprintf("Error while invoking getCallerIdentity sdk call: %v", err))
+	}
+	if roleArn, err := ParseArn(crossAccRoleArn); err == nil {
+		return roleArn.AccountID
+	}
+	return ""
+}
+
+func getLogGroupNames(
+	ctx context.Context,
+	sel *selection,
+	ignoreAccessLogSettings bool,
+	strictLogGroupFormat bool,
+	failOnUnmatched bool,
+	conn AwsApiGatewayProvider,
+	stageInventory *inventory,
+	collector *findingCollector) []string {
+	for _, entry := range sel.invalid {
+		collector.addSelectorFinding(WrongSyntax, FindingSeverityError, entry)
+	}
+	accountErrors := len(collector.accountErrors)
+
+	accessLogFormatKeysMap := make(map[string]*accessLogFormatKeys)
+	logGroupNames := getLogGroupNamesRestApis(
+		ctx,
+		conn,
+		sel,
+		ignoreAccessLogSettings,
+		accessLogFormatKeysMap,
+		stageInventory,
+		collector)
+
+	apiGatewayV2LogGroupNames := getLogGroupNamesHttpApis(
+		ctx,
+		conn,
+		sel,
+		ignoreAccessLogSettings,
+		accessLogFormatKeysMap,
+		stageInventory,
+		collector)
+	logGroupNames = append(logGroupNames, apiGatewayV2LogGroupNames...)
+
+	// entries can only be told apart from deleted or mistyped apis when every api was listed
+	if len(collector.accountErrors) == accountErrors {
+		unmatchedSeverity := FindingSeverityWarning
+		if failOnUnmatched {
+			unmatchedSeverity = FindingSeverityError
+		}
+		for _, entry := range sel.unmatched() {
+			collector.addSelectorFinding(SelectorUnmatched, unmatchedSeverity, entry)
+		}
+	}
+
+	// stages writing different keys to the same log group break log parsing, in strict
+	// mode such log groups are reported as errors and left out
+	var conflictingLogGroupNames []string
+	formatLogGroupNames := make([]string, 0, len(accessLogFormatKeysMap))
+	for logGroupName := range accessLogFormatKeysMap {
+		formatLogGroupNames = append(formatLogGroupNames, logGroupName)
+	}
+	sort.Strings(formatLogGroupNames)
+	for _, logGroupName := range formatLogGroupNames {
+		formatMap := accessLogFormatKeysMap[logGroupName]
+		if len(formatMap.conflicts) == 0 {
+			continue
+		}
+		summary := AccessLogFormatKeyMismatch.new(withLogGroupName(logGroupName), withValues(formatMap.conflictingValues()), withAccount(collector.account))
+		if strictLogGroupFormat {
+			summary = AccessLogFormatKeyMismatchExcluded.new(withLogGroupName(logGroupName), withValues(formatMap.conflictingValues()), withAccount(collector.account))
+			collector.addWithDetail(AccessLogFormatKeyMismatchExcluded, FindingSeverityError, summary, formatMap.conflictDetail())
+			conflictingLogGroupNames = append(conflictingLogGroupNames, logGroupName)
+		} else {
+			collector.addWithDetail(AccessLogFormatKeyMismatch, FindingSeverityWarning, summary, formatMap.conflictDetail())
+		}
+	}
+	stageInventory.removeLogGroups(conflictingLogGroupNames)
+	var filteredLogGroupNames []string
+	for _, logGroupName := range logGroupNames {
+		if !contains(conflictingLogGroupNames, logGroupName) {
+			filteredLogGroupNames = append(filteredLogGroupNames, logGroupName)
+		}
+	}
+	return removeDuplicates(filteredLogGroupNames)
+}
+
+func getLogGroupNamesRestApis(
+	ctx context.Context,
+	conn AwsApiGatewayProvider,
+	sel *selection,
+	ignoreAccessLogSettings bool,
+	accessLogFormatKeysMap map[string]*accessLogFormatKeys,
+	stageInventory *inventory,
+	collector *findingCollector) []string {
+	// restApiIds are the apis whose stages need to be listed
+	var restApiIds []string
+	restApisPaginator := conn.GetAwsGetRestApisPaginator()
+	for restApisPaginator.HasMorePages() {
+		res, err := restApisPaginator.NextPage(ctx)
+		if err != nil {
+			summary := fmt.Sprintf("Error while invoking getRestApis sdk call: %s", err.Error())
+			collector.addAccountError(summary)
+			// restApisPaginator.HasMorePages() will return true even if there are connection issues
+			return []string{}
+		}
+		for _, restApi := range res.Items {
+			if restApi.Id == nil {
+				collector.addAccountError("getRestApis returned an API without an ID")
+				continue
+			}
+			apiId := aws.ToString(restApi.Id)
+			sel.setApiName(apiId, aws.ToString(restApi.Name))
+			if sel.needsStages(apiId) {
+				restApiIds = append(restApiIds, apiId)
+			} else {
+				sel.skipApi(apiId)
+				stageInventory.addSelection(sel.explain(apiId, "", sel.decide(apiId, "")))
+			}
+		}
+	}
+	logGroupNames := getLogGroupNamesRestApisHelper(
+		ctx,
+		conn,
+		restApiIds,
+		sel,
+		ignoreAccessLogSettings,
+		accessLogFormatKeysMap,
+		stageInventory,
+		collector)
+	return logGroupNames
+}
+
+func getLogGroupNamesHttpApis(
+	ctx context.Context,
+	conn AwsApiGatewayProvider,
+	sel *selection,
+	ignoreAccessLogSettings bool,
+	accessLogFormatKeysMap map[string]*accessLogFormatKeys,
+	stageInventory *inventory,
+	collector *findingCollector) []string {
+	var summary string
+	// httpApiIds and webSocketApiIds are the apis whose stages need to be listed
+	var httpApiIds, webSocketApiIds []string
+	apiGatewayV2Client := conn.GetApiGatewayV2Client()
+	apis, err := getApis(ctx, apiGatewayV2Client)
+	if err != nil {
+		summary = fmt.Sprintf("Error while invoking getApis sdk call: %s", err.Error())
+		collector.addAccountError(summary)
+		return []string{}
+	}
+	for _, httpApi := range apis {
+		if httpApi.ApiId == nil {
+			collector.addAccountError("getApis returned an API without an ID")
+			continue
+		}
+		apiId := aws.ToString(httpApi.ApiId)
+		sel.setApiName(apiId, aws.ToString(httpApi.Name))
+		webSocket := httpApi.ProtocolType == v2types.ProtocolTypeWebsocket
+		switch {
+		case !webSocket && ignoreAccessLogSettings:
+			sel.skipApi(apiId)
+			stageInventory.addSelection(Selection{
+				ApiId:   apiId,
+				ApiName: aws.ToString(httpApi.Name),
+				Reason:  "excluded, HTTP APIs only have access logs, which ignore_access_log_settings leaves out",
+			})
+		case !sel.needsStages(apiId):
+			sel.skipApi(apiId)
+			stageInventory.addSelection(sel.explain(apiId, "", sel.decide(apiId, "")))
+		case webSocket:
+			webSocketApiIds = append(webSocketApiIds, apiId)
+		default:
+			httpApiIds = append(httpApiIds, apiId)
+		}
+	}
+	logGroupNames := getLogGroupNamesWebSocketApisHelper(
+		ctx,
+		conn,
+		webSocketApiIds,
+		sel,
+		ignoreAccessLogSettings,
+		accessLogFormatKeysMap,
+		stageInventory,
+		collector)
+	logGroupNames = append(logGroupNames, getLogGroupNamesHttpApisHelper(
+		ctx,
+		conn,
+		httpApiIds,
+		sel,
+		accessLogFormatKeysMap,
+		stageInventory,
+		collector)...)
+	return logGroupNames
+}
+
+// getApis reads every page of GetApis, the SDK has no paginator for apigatewayv2.
+func getApis(ctx context.Context, client AwsApiGatewayV2Client) ([]v2types.Api, error) {
+	var apis []v2types.Api
+	params := &v2.GetApisInput{}
+	for {
+		res, err := client.GetApis(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		apis = append(apis, res.Items...)
+		if aws.ToString(res.NextToken) == "" {
+			return apis, nil
+		}
+		params.NextToken = res.NextToken
+	}
+}
+
+// getStagesV2 reads every page of the GetStages of an HTTP or WebSocket api.
+func getStagesV2(ctx context.Context, client AwsApiGatewayV2Client, apiId string) ([]v2types.Stage, error) {
+	var stages []v2types.Stage
+	params := &v2.GetStagesInput{ApiId: &apiId}
+	for {
+		res, err := client.GetStages(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		stages = append(stages, res.Items...)
+		if aws.ToString(res.NextToken) == "" {
+			return stages, nil
+		}
+		params.NextToken = res.NextToken
+	}
+}
+
+func getLogGroupNamesRestApisHelper(
+	ctx context.Context,
+	conn AwsApiGatewayProvider,
+	apiIds []string,
+	sel *selection,
+	ignoreAccessLogSettings bool,
+	accessLogFormatKeysMap map[string]*accessLogFormatKeys,
+	stageInventory *inventory,
+	collector *findingCollector) []string {
+
+	var logGroupNames []string
+	apiGatewayClient := conn.GetApiGatewayClient()
+	for _, apiId := range apiIds {
+		res, err := apiGatewayClient.GetStages(ctx, &v1.GetStagesInput{
+			RestApiId: &apiId,
+		})
+		if err != nil {
+			summary := fmt.Sprintf("Error while invoking getStages sdk call: %s", err.Error())
+			collector.addAccountError(summary)
+			continue
+		}
+		for _, stage := range res.Item {
+			if stage.StageName == nil {
+				collector.addAccountError(fmt.Sprintf("getStages returned a stage without a name for %s", apiId))
+				continue
+			}
+			stageName := aws.ToString(stage.StageName)
+			apiIdWithStageName := strings.Join([]string{apiId, stageName}, "/")
+			d := sel.decide(apiId, stageName)
+			stageInventory.addSelection(sel.explain(apiId, stageName, d))
+			if !d.selected {
+				continue
+			}
+			stageDetails := stageInfo{
+				apiId:     apiId,
+				stageName: stageName,
+				apiType:   RestApiType,
+			}
+			stageDetails.executionLogging, stageDetails.methodOverrides = getExecutionLogging(stage.MethodSettings)
+			if verifyExecutionLogging(stageDetails, apiIdWithStageName, collector) {
+				logGroupNames = append(logGroupNames, ExecutionLogGroupName(apiId, stageName))
+				stageDetails.selectedLogGroups = append(stageDetails.selectedLogGroups, ExecutionLogGroupName(apiId, stageName))
+			}
+			if ignoreAccessLogSettings {
+				stageInventory.add(stageDetails)
+				continue
+			}
+			var destinationArn, format *string
+			if stage.AccessLogSettings != nil {
+				destinationArn, format = stage.AccessLogSettings.DestinationArn, stage.AccessLogSettings.Format
+			}
+			if logGroupName := verifyAccessLogSettings(destinationArn, format, AccessLogNotEnabledREST, AccessLogFormatMandatoryValues, &stageDetails, accessLogFormatKeysMap, collector); logGroupName != "" {
+				logGroupNames = append(logGroupNames, logGroupName)
+			}
+			stageInventory.add(stageDetails)
+		}
+	}
+	return logGroupNames
+}
+
+func getLogGroupNamesHttpApisHelper(
+	ctx context.Context,
+	conn AwsApiGatewayProvider,
+	apiIds []string,
+	sel *selection,
+	accessLogFormatKeysMap map[string]*accessLogFormatKeys,
+	stageInventory *inventory,
+	collector *findingCollector) []string {
+	var logGroupNames []string
+	apiGatewayV2Client := conn.GetApiGatewayV2Client()
+	for _, apiId := range apiIds {
+		stages, err := getStagesV2(ctx, apiGatewayV2Client, apiId)
+		if err != nil {
+			summary := fmt.Sprintf("Error while invoking getStages sdk call: %s", err.Error())
+			collector.addAccountError(summary)
+			continue
+		}
+		for _, stage := range stages {
+			if stage.StageName == nil {
+				collector.addAccountError(fmt.Sprintf("getStages returned a stage without a name for %s", apiId))
+				continue
+			}
+			stageName := aws.ToString(stage.StageName)
+			d := sel.decide(apiId, stageName)
+			stageInventory.addSelection(sel.explain(apiId, stageName, d))
+			if !d.selected {
+				continue
+			}
+			stageDetails := stageInfo{
+				apiId:     apiId,
+				stageName: stageName,
+				apiType:   HttpApiType,
+			}
+			var destinationArn, format *string
+			if stage.AccessLogSettings != nil {
+				destinationArn, format = stage.AccessLogSettings.DestinationArn, stage.AccessLogSettings.Format
+			}
+			if logGroupName := verifyAccessLogSettings(destinationArn, format, AccessLogNotEnabledHTTP, AccessLogFormatMandatoryValues, &stageDetails, accessLogFormatKeysMap, collector); logGroupName != "" {
+				logGroupNames = append(logGroupNames, logGroupName)
+			}
+			stageInventory.add(stageDetails)
+		}
+	}
+	return logGroupNames
+}
+
+func getLogGroupNamesWebSocketApisHelper(
+	ctx context.Context,
+	conn AwsApiGatewayProvider,
+	apiIds []string,
+	sel *selection,
+	ignoreAccessLogSettings bool,
+	accessLogFormatKeysMap map[string]*accessLogFormatKeys,
+	stageInventory *inventory,
+	collector *findingCollector) []string {
+	var logGroupNames []string
+	apiGatewayV2Client := conn.GetApiGatewayV2Client()
+	for _, apiId := range apiIds {
+		stages, err := getStagesV2(ctx, apiGatewayV2Client, apiId)
+		if err != nil {
+			summary := fmt.Sprintf("Error while invoking getStages sdk call: %s", err.Error())
+			collector.addAccountError(summary)
+			continue
+		}
+		for _, stage := range stages {
+			if stage.StageName == nil {
+				collector.addAccountError(fmt.Sprintf("getStages returned a stage without a name for %s", apiId))
+				continue
+			}
+			stageName := aws.ToString(stage.StageName)
+			apiIdWithStageName := strings.Join([]string{apiId, stageName}, "/")
+			d := sel.decide(apiId, stageName)
+			stageInventory.addSelection(sel.explain(apiId, stageName, d))
+			if !d.selected {
+				continue
+			}
+			stageDetails := stageInfo{
+				apiId:     apiId,
+				stageName: stageName,
+				apiType:   WebSocketApiType,
+			}
+			stageDetails.executionLogging, stageDetails.methodOverrides = getRouteExecutionLogging(stage.DefaultRouteSettings, stage.RouteSettings)
+			if verifyExecutionLogging(stageDetails, apiIdWithStageName, collector) {
+				logGroupNames = append(logGroupNames, getWebSocketExecutionLogGroupName(apiId, stageName))
+				stageDetails.selectedLogGroups = append(stageDetails.selectedLogGroups, getWebSocketExecutionLogGroupName(apiId, stageName))
+			}
+			if ignoreAccessLogSettings {
+				stageInventory.add(stageDetails)
+				continue
+			}
+			var destinationArn, format *string
+			if stage.AccessLogSettings != nil {
+				destinationArn, format = stage.AccessLogSettings.DestinationArn, stage.AccessLogSettings.Format
+			}
+			if logGroupName := verifyAccessLogSettings(destinationArn, format, AccessLogNotEnabledWebSocket, WebSocketAccessLogFormatMandatoryValues, &stageDetails, accessLogFormatKeysMap, collector); logGroupName != "" {
+				logGroupNames = append(logGroupNames, logGroupName)
+			}
+			stageInventory.add(stageDetails)
+		}
+	}
+	return logGroupNames
+}
+
+// verifyAccessLogSettings reports the access log settings of a stage and returns its log group.
+func verifyAccessLogSettings(destinationArn *string, format *string, notEnabled Summary, mandatoryValues []string,
+	stageDetails *stageInfo, accessLogFormatKeysMap map[string]*accessLogFormatKeys, collector *findingCollector) string {
+
+	apiIdWithStageName := stageDetails.apiIdWithStageName()
+	if destinationArn == nil {
+		collector.addError(notEnabled, apiIdWithStageName)
+		return ""
+	}
+	if err := ValidateLogGroupArn(*destinationArn); err != nil {
+		collector.addError(AccessLogDestinationNotLogGroup, apiIdWithStageName, withDestination(*destinationArn))
+		return ""
+	}
+	logGroupName := AccessLogGroupNameFromArn(*destinationArn)
+	stageDetails.accessLogGroup = logGroupName
+	if format == nil {
+		collector.addError(AccessLogFormatMissing, apiIdWithStageName)
+		return ""
+	}
+	if !verifyAccessLogFormat(*format, mandatoryValues, stageDetails, logGroupName, accessLogFormatKeysMap, collector) {
+		return ""
+	}
+	stageDetails.selectedLogGroups = append(stageDetails.selectedLogGroups, logGroupName)
+	return logGroupName
+}
+
+// verifyExecutionLogging returns whether the stage sends full request and response logs.
+func verifyExecutionLogging(stageDetails stageInfo, apiIdWithStageName string, collector *findingCollector) bool {
+	for _, methodOverride := range stageDetails.methodOverrides {
+		collector.addStageWarn(ExecutionLogMethodOverride, apiIdWithStageName, fmt.Sprintf("%s %s", apiIdWithStageName, methodOverride))
+	}
+	switch stageDetails.executionLogging {
+	case ExecutionLoggingFull:
+		return true
+	case ExecutionLoggingInfo:
+		collector.addError(FullRequestAndResponseLogNotEnabled, apiIdWithStageName)
+	case ExecutionLoggingError:
+		collector.addError(ExecutionLogErrorOnly, apiIdWithStageName)
+	case ExecutionLoggingUnconfigured:
+		collector.addError(ExecutionLogNotConfigured, apiIdWithStageName)
+	case ExecutionLoggingLevelMissing:
+		collector.addError(ExecutionLogLevelMissing, apiIdWithStageName)
+	default:
+		collector.addError(ExecutionLogNotEnabled, apiIdWithStageName)
+	}
+	return false
+}
+
+// getExecutionLogging returns the logging of */* and of the methods overriding it.
+func getExecutionLogging(methodSettings map[string]v1types.MethodSetting) (ExecutionLogging, []methodLoggingSettings) {
+	defaultSettings, configured := methodSettings[defaultMethodSettingsKey]
+	executionLogging := ExecutionLoggingUnconfigured
+	if configured {
+		executionLogging = getExecutionLoggingFromLevel(aws.ToString(defaultSettings.LoggingLevel), defaultSettings.DataTraceEnabled)
+	}
+
+	var methods []string
+	for method := range methodSettings {
+		if method != defaultMethodSettingsKey {
+			methods = append(methods, method)
+		}
+	}
+	sort.Strings(methods)
+
+	var methodOverrides []methodLoggingSettings
+	for _, method := range methods {
+		settings := methodSettings[method]
+		if configured && aws.ToString(settings.LoggingLevel) == aws.ToString(defaultSettings.LoggingLevel) &&
+			settings.DataTraceEnabled == defaultSettings.DataTraceEnabled {
+			continue
+		}
+		methodOverrides = append(methodOverrides, methodLoggingSettings{
+			method:           getMethodPath(method),
+			loggingLevel:     aws.ToString(settings.LoggingLevel),
+			dataTraceEnabled: settings.DataTraceEnabled,
+		})
+	}
+	return executionLogging, methodOverrides
+}
+
+// getRouteExecutionLogging returns the logging of the default route and of the routes overriding it.
+func getRouteExecutionLogging(defaultRouteSettings *v2types.RouteSettings, routeSettings map[string]v2types.RouteSettings) (ExecutionLogging, []methodLoggingSettings) {
+	executionLogging := ExecutionLoggingUnconfigured
+	if defaultRouteSettings != nil {
+		executionLogging = getExecutionLoggingFromLevel(string(defaultRouteSettings.LoggingLevel), aws.ToBool(defaultRouteSettings.DataTraceEnabled))
+	}
+
+	var routes []string
+	for route := range routeSettings {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	var routeOverrides []methodLoggingSettings
+	for _, route := range routes {
+		settings := routeSettings[route]
+		if defaultRouteSettings != nil && settings.LoggingLevel == defaultRouteSettings.LoggingLevel &&
+			aws.ToBool(settings.DataTraceEnabled) == aws.ToBool(defaultRouteSettings.DataTraceEnabled) {
+			continue
+		}
+		routeOverrides = append(routeOverrides, methodLoggingSettings{
+			method:           route,
+			loggingLevel:     string(settings.LoggingLevel),
+			dataTraceEnabled: aws.ToBool(settings.DataTraceEnabled),
+		})
+	}
+	return executionLogging, routeOverrides
+}
+
+func getExecutionLoggingFromLevel(loggingLevel string, dataTraceEnabled bool) ExecutionLogging {
+	if loggingLevel == "" {
+		return ExecutionLoggingLevelMissing
+	} else if loggingLevel == "INFO" && dataTraceEnabled {
+		return ExecutionLoggingFull
+	} else if loggingLevel == "INFO" {
+		return ExecutionLoggingInfo
+	} else if loggingLevel == "ERROR" {
+		return ExecutionLoggingError
+	}
+	return ExecutionLoggingOff
+}
+
+func verifyAccessLogFormat(format string, mandatoryValues []string, stageDetails *stageInfo, logGroupName string,
+	accessLogFormatKeysMap map[string]*accessLogFormatKeys, collector *findingCollector) bool {
+
+	apiIdWithStageName := stageDetails.apiIdWithStageName()
+	variableKeys, missingValues, err := AnalyzeAccessLogFormat(format, mandatoryValues)
+	stageDetails.accessLogFormat = &accessLogFormatAnalysis{
+		format:        format,
+		json:          err == nil,
+		variableKeys:  variableKeys,
+		missingValues: missingValues,
+	}
+	if err != nil {
+		collector.addError(AccessLogFormatNotJson, apiIdWithStageName)
+		return false
+	}
+	if len(missingValues) > 0 {
+		collector.addError(AccessLogFormatMissingRequiredValues, apiIdWithStageName, withMissingValues(missingValues))
+		return false
+	}
+	accessLogKeys := make(map[string]AccessLogFormatKey, len(variableKeys))
+	for variable, key := range variableKeys {
+		accessLogKeys[variable] = AccessLogFormatKey{
+			key:                key,
+			apiIdWithStageName: apiIdWithStageName,
+		}
+	}
+	if storedMap, found := accessLogFormatKeysMap[logGroupName]; found {
+		storedMap.formats[apiIdWithStageName] = format
+		values := make([]string, 0, len(accessLogKeys))
+		for value := range accessLogKeys {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			key := accessLogKeys[value]
+			if storedKey, valueFound := storedMap.valueToKey[value]; valueFound {
+				if key.key != storedKey.key {
+					storedMap.conflicts = append(storedMap.conflicts, AccessLogFormatConflict{
+						value:  value,
+						first:  storedKey,
+						second: key,
+					})
+				}
+			} else {
+				storedMap.valueToKey[value] = key
+			}
+		}
+	} else {
+		accessLogFormatKeysMap[logGroupName] = &accessLogFormatKeys{
+			valueToKey: accessLogKeys,
+			formats:    map[string]string{apiIdWithStageName: format},
+		}
+	}
+	return true
+}
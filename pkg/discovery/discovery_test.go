@@ -0,0 +1,1015 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery/cassette"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v1 "github.com/aws/aws-sdk-go-v2/service/apigateway"
+	v1types "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
+	v2 "github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	v2types "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name:     "empty map",
+			input:    make(map[string]interface{}),
+			expected: make(map[string]interface{}),
+		},
+		{
+			name: "flat json map",
+			input: map[string]interface{}{
+				"stringKey":   "stringValue",
+				"numericKey":  1234,
+				"booleanKey":  true,
+				"floatingKey": 123.4,
+			},
+			expected: map[string]interface{}{
+				"stringKey":   "stringValue",
+				"numericKey":  1234,
+				"booleanKey":  true,
+				"floatingKey": 123.4,
+			},
+		},
+		{
+			name: "json map with arrays",
+			input: map[string]interface{}{
+				"arrayKey": []interface{}{"first", map[string]interface{}{"nestedKey": "second"}},
+			},
+			expected: map[string]interface{}{
+				"arrayKey.0":           "first",
+				"arrayKey.1.nestedKey": "second",
+			},
+		},
+		{
+			name: "nested json map",
+			input: map[string]interface{}{
+				"string.Key": "string.Value",
+				"nestedKey": map[string]interface{}{
+					"level1.a": map[string]interface{}{
+						"numericKey": 1234,
+						"booleanKey": true,
+					},
+					"stringKey": "StringValue",
+				},
+				"floatingKey": 123.4,
+			},
+			expected: map[string]interface{}{
+				"string.Key":                    "string.Value",
+				"nestedKey.level1.a.numericKey": 1234,
+				"nestedKey.level1.a.booleanKey": true,
+				"nestedKey.stringKey":           "StringValue",
+				"floatingKey":                   123.4,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Flatten(test.input))
+		})
+	}
+}
+
+func TestFixAccessLogFormatMissingQuotes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "not json",
+			input:    `"key1": "value1", "key2", "key3"`,
+			expected: `"key1": "value1", "key2", "key3"`,
+		},
+		{
+			name:     "flat json: no values with missing quotes",
+			input:    `{"key1":"$context.requestTime", "key2":"$context.path", "key3":"$context.requestId"}`,
+			expected: `{"key1":"$context.requestTime", "key2":"$context.path", "key3":"$context.requestId"}`,
+		},
+		{
+			name:     "nested json: no values with missing quotes",
+			input:    `{"key1": "$context.path", "nested.key":{"level1":"$context.url", "level1b":"$context.Status"}}`,
+			expected: `{"key1": "$context.path", "nested.key":{"level1":"$context.url", "level1b":"$context.Status"}}`,
+		},
+		{
+			name:     "flat json: values with missing quotes",
+			input:    `{"key1":"$context.requestTime", "key2":$context.path, "key3":  $context.requestId}`,
+			expected: `{"key1":"$context.requestTime", "key2":"$context.path", "key3":"$context.requestId"}`,
+		},
+		{
+			name:     "nested json: values with missing quotes",
+			input:    `{"key1": "$context.path"  , "nested.key":{ "level1":  $context.Status, "level1b" :  $context.identity.sourceIp , "level1c":"v"}}`,
+			expected: `{"key1": "$context.path"  , "nested.key":{ "level1":"$context.Status", "level1b" :"$context.identity.sourceIp" , "level1c":"v"}}`,
+		},
+		{
+			name:     "array values with missing quotes",
+			input:    `{"key1": [$context.path, "$context.status",$context.stage ]}`,
+			expected: `{"key1": ["$context.path", "$context.status","$context.stage" ]}`,
+		},
+		{
+			name:     "variables with hyphens and brackets",
+			input:    `{"groups": $context.authorizer.claims['cognito:groups'], "header":$context.requestOverride.header.x-trace-id}`,
+			expected: `{"groups":"$context.authorizer.claims['cognito:groups']", "header":"$context.requestOverride.header.x-trace-id"}`,
+		},
+		{
+			name:     "stage variables with missing quotes",
+			input:    `{"env": $stageVariables.env, "path": $context.path}`,
+			expected: `{"env":"$stageVariables.env", "path":"$context.path"}`,
+		},
+		{
+			name:     "interpolation inside a string",
+			input:    `{"u":"$context.path?$context.requestOverride.querystring.q", "m": $context.httpMethod}`,
+			expected: `{"u":"$context.path?$context.requestOverride.querystring.q", "m":"$context.httpMethod"}`,
+		},
+		{
+			name:     "interpolation with missing quotes",
+			input:    `{"u": $context.domainName$context.path , "s":$context.status}`,
+			expected: `{"u":"$context.domainName$context.path" , "s":"$context.status"}`,
+		},
+		{
+			name:     "escaped quotes inside a string",
+			input:    `{"q":"say \"$context.path\"", "s": $context.status}`,
+			expected: `{"q":"say \"$context.path\"", "s":"$context.status"}`,
+		},
+		{
+			name:     "common log format",
+			input:    `$context.identity.sourceIp [$context.requestTime] "$context.httpMethod $context.path"`,
+			expected: `"$context.identity.sourceIp" ["$context.requestTime"] "$context.httpMethod $context.path"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, fixAccessLogFormatMissingQuotes(test.input))
+		})
+	}
+}
+
+func FuzzFixAccessLogFormatMissingQuotes(f *testing.F) {
+	for _, seed := range []string{
+		`"key1": "value1", "key2", "key3"`,
+		`{"key1":"$context.requestTime", "key2":$context.path, "key3":  $context.requestId}`,
+		`{"key1": "$context.path"  , "nested.key":{ "level1":  $context.Status, "level1b" :  $context.identity.sourceIp , "level1c":"v"}}`,
+		`{"key1": [$context.path, "$context.status",$context.stage ]}`,
+		`{"groups": $context.authorizer.claims['cognito:groups'], "env": $stageVariables.env}`,
+		`{"u":"$context.path?$context.requestOverride.querystring.q", "q":"\"$context.status\""}`,
+		`$context.identity.sourceIp [$context.requestTime] "$context.httpMethod $context.path"`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, format string) {
+		fixed := fixAccessLogFormatMissingQuotes(format)
+		assert.Equal(t, fixed, fixAccessLogFormatMissingQuotes(fixed), "fixing is not idempotent")
+		if json.Valid([]byte(format)) {
+			assert.Equal(t, format, fixed, "valid JSON was modified")
+		}
+		var before, after []string
+		for _, variable := range ParseAccessLogFormat(format).Variables() {
+			before = append(before, variable.Text)
+		}
+		for _, variable := range ParseAccessLogFormat(fixed).Variables() {
+			after = append(after, variable.Text)
+		}
+		assert.Equal(t, before, after, "variables were modified")
+	})
+}
+
+func TestVerifyAccessLogFormatKeyConflicts(t *testing.T) {
+	accessLogFormatKeysMap := make(map[string]*accessLogFormatKeys)
+	collector := newFindingCollector()
+	first := `{"method":"$context.httpMethod", "domain":"$context.domainName", "status":"$context.status", "path":"$context.path"}`
+	second := `{"method":"$context.httpMethod", "domain":"$context.domainName", "status":"$context.status", "resourcePath":"$context.path"}`
+
+	assert.True(t, verifyAccessLogFormat(first, AccessLogFormatMandatoryValues, &stageInfo{apiId: "api1", stageName: "dev"}, "logGroup", accessLogFormatKeysMap, collector))
+	assert.True(t, verifyAccessLogFormat(second, AccessLogFormatMandatoryValues, &stageInfo{apiId: "api2", stageName: "prod"}, "logGroup", accessLogFormatKeysMap, collector))
+
+	formatMap := accessLogFormatKeysMap["logGroup"]
+	assert.Equal(t, []AccessLogFormatConflict{
+		{
+			value:  "$context.path",
+			first:  AccessLogFormatKey{key: "path", apiIdWithStageName: "api1/dev"},
+			second: AccessLogFormatKey{key: "resourcePath", apiIdWithStageName: "api2/prod"},
+		},
+	}, formatMap.conflicts)
+	assert.Equal(t, []string{"$context.path"}, formatMap.conflictingValues())
+
+	detail := formatMap.conflictDetail()
+	assert.Contains(t, detail, `$context.path: key "path" in api1/dev, key "resourcePath" in api2/prod`)
+	assert.Contains(t, detail, "Format of api1/dev: "+first)
+	assert.Contains(t, detail, "Format of api2/prod: "+second)
+}
+
+// A variable used under several keys is given the same key for every stage, whatever the
+// order of the keys of the parsed format.
+func TestVerifyAccessLogFormatSharedVariable(t *testing.T) {
+	format := `{"method":"$context.httpMethod", "domain":"$context.domainName", "status":"$context.status", ` +
+		`"path":"$context.path", "url":"$context.domainName$context.path"}`
+	for i := 0; i < 200; i++ {
+		variableKeys, _, err := AnalyzeAccessLogFormat(format, AccessLogFormatMandatoryValues)
+		assert.NoError(t, err)
+		assert.Equal(t, "path", variableKeys["$context.path"])
+		assert.Equal(t, "domain", variableKeys["$context.domainName"])
+
+		accessLogFormatKeysMap := make(map[string]*accessLogFormatKeys)
+		collector := newFindingCollector()
+		assert.True(t, verifyAccessLogFormat(format, AccessLogFormatMandatoryValues, &stageInfo{apiId: "api1", stageName: "dev"}, "logGroup", accessLogFormatKeysMap, collector))
+		assert.True(t, verifyAccessLogFormat(format, AccessLogFormatMandatoryValues, &stageInfo{apiId: "api2", stageName: "prod"}, "logGroup", accessLogFormatKeysMap, collector))
+		assert.Empty(t, accessLogFormatKeysMap["logGroup"].conflicts)
+	}
+	// without a key holding the variable alone, the first key in sorted order is kept
+	variableKeys, _, err := AnalyzeAccessLogFormat(`{"b":"$context.path?x", "a":"$context.path!"}`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"$context.path": "a"}, variableKeys)
+}
+
+// Log groups with conflicting keys are reported in the same order, with the same values and
+// detail, at every discovery.
+func TestGetLogGroupNamesKeyConflictsOrder(t *testing.T) {
+	first := aws.String(`{"method":"$context.httpMethod", "domain":"$context.domainName", "status":"$context.status", ` +
+		`"path":"$context.path", "ip":"$context.identity.sourceIp", "agent":"$context.identity.userAgent"}`)
+	second := aws.String(`{"verb":"$context.httpMethod", "host":"$context.domainName", "code":"$context.status", ` +
+		`"resourcePath":"$context.path", "sourceIp":"$context.identity.sourceIp", "userAgent":"$context.identity.userAgent"}`)
+	conn := &fakeApiGatewayProvider{restStages: map[string][]v1types.Stage{}}
+	for _, logGroup := range []string{"logs-c", "logs-a", "logs-d", "logs-b"} {
+		destinationArn := aws.String("arn:aws:logs:us-east-1:123456789012:log-group:" + logGroup)
+		for i, format := range []*string{first, second} {
+			apiId := fmt.Sprintf("%s-%d", logGroup, i)
+			conn.restApis = append(conn.restApis, v1types.RestApi{Id: aws.String(apiId)})
+			conn.restStages[apiId] = []v1types.Stage{{
+				StageName:         aws.String("dev"),
+				MethodSettings:    map[string]v1types.MethodSetting{"*/*": {LoggingLevel: aws.String("INFO"), DataTraceEnabled: true}},
+				AccessLogSettings: &v1types.AccessLogSettings{DestinationArn: destinationArn, Format: format},
+			}}
+		}
+	}
+
+	var expected []Finding
+	for i := 0; i < 20; i++ {
+		collector := newFindingCollector()
+		getLogGroupNames(context.Background(), newSelection(Selector{}), false, false, false, conn, &inventory{}, collector)
+		if i == 0 {
+			expected = collector.findings
+			assert.Len(t, expected, 4)
+			continue
+		}
+		assert.Equal(t, expected, collector.findings)
+	}
+}
+
+func TestGetExecutionLogging(t *testing.T) {
+	tests := []struct {
+		name                     string
+		input                    map[string]v1types.MethodSetting
+		expectedExecutionLogging ExecutionLogging
+		expectedMethodOverrides  []methodLoggingSettings
+	}{
+		{
+			name:                     "no method settings",
+			input:                    map[string]v1types.MethodSetting{},
+			expectedExecutionLogging: ExecutionLoggingUnconfigured,
+		},
+		{
+			name: "default method settings only",
+			input: map[string]v1types.MethodSetting{
+				"*/*": {LoggingLevel: aws.String("INFO"), DataTraceEnabled: true},
+			},
+			expectedExecutionLogging: ExecutionLoggingFull,
+		},
+		{
+			name: "overrides matching the default are not reported",
+			input: map[string]v1types.MethodSetting{
+				"*/*":           {LoggingLevel: aws.String("ERROR")},
+				"~1orders/POST": {LoggingLevel: aws.String("ERROR")},
+			},
+			expectedExecutionLogging: ExecutionLoggingError,
+		},
+		{
+			name: "overrides turning logging off",
+			input: map[string]v1types.MethodSetting{
+				"*/*":                {LoggingLevel: aws.String("INFO"), DataTraceEnabled: true},
+				"~1orders/POST":      {LoggingLevel: aws.String("OFF")},
+				"~1orders~1{id}/GET": {LoggingLevel: aws.String("INFO")},
+			},
+			expectedExecutionLogging: ExecutionLoggingFull,
+			expectedMethodOverrides: []methodLoggingSettings{
+				{method: "/orders/POST", loggingLevel: "OFF"},
+				{method: "/orders/{id}/GET", loggingLevel: "INFO"},
+			},
+		},
+		{
+			name: "overrides without default method settings",
+			input: map[string]v1types.MethodSetting{
+				"~1orders/POST": {LoggingLevel: aws.String("INFO"), DataTraceEnabled: true},
+			},
+			expectedExecutionLogging: ExecutionLoggingUnconfigured,
+			expectedMethodOverrides: []methodLoggingSettings{
+				{method: "/orders/POST", loggingLevel: "INFO", dataTraceEnabled: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			executionLogging, methodOverrides := getExecutionLogging(test.input)
+			assert.Equal(t, test.expectedExecutionLogging, executionLogging)
+			assert.Equal(t, test.expectedMethodOverrides, methodOverrides)
+		})
+	}
+}
+
+func TestGetLogGroupNamesMissingFields(t *testing.T) {
+	destinationArn := aws.String("arn:aws:logs:us-east-1:123456789012:log-group:access-logs")
+	format := aws.String(`{"method":"$context.httpMethod", "domain":"$context.domainName", "status":"$context.status", "path":"$context.path"}`)
+
+	tests := []struct {
+		name                string
+		restStage           *v1types.Stage
+		httpStage           *v2types.Stage
+		expectedLogGroups   []string
+		expectedSummaries   []string
+		expectedInventory   []stageInfo
+		ignoreAccessLogging bool
+	}{
+		{
+			name: "rest stage without logging level",
+			restStage: &v1types.Stage{
+				StageName:         aws.String("dev"),
+				MethodSettings:    map[string]v1types.MethodSetting{"*/*": {DataTraceEnabled: true}},
+				AccessLogSettings: &v1types.AccessLogSettings{DestinationArn: destinationArn, Format: format},
+			},
+			expectedLogGroups: []string{"access-logs"},
+			expectedSummaries: []string{"Execution Log level missing for api1/dev"},
+			expectedInventory: []stageInfo{
+				{apiId: "api1", stageName: "dev", apiType: RestApiType, executionLogging: ExecutionLoggingLevelMissing, accessLogGroup: "access-logs"},
+			},
+		},
+		{
+			name: "rest method override without logging level",
+			restStage: &v1types.Stage{
+				StageName: aws.String("dev"),
+				MethodSettings: map[string]v1types.MethodSetting{
+					"*/*":           {LoggingLevel: aws.String("INFO"), DataTraceEnabled: true},
+					"~1orders/POST": {},
+				},
+				AccessLogSettings: &v1types.AccessLogSettings{DestinationArn: destinationArn, Format: format},
+			},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_api1/dev", "access-logs"},
+			expectedSummaries: []string{"Execution Log settings overridden for api1/dev /orders/POST (logging level missing)"},
+			expectedInventory: []stageInfo{
+				{
+					apiId: "api1", stageName: "dev", apiType: RestApiType, executionLogging: ExecutionLoggingFull, accessLogGroup: "access-logs",
+					methodOverrides: []methodLoggingSettings{{method: "/orders/POST"}},
+				},
+			},
+		},
+		{
+			name: "rest stage without access log settings",
+			restStage: &v1types.Stage{
+				StageName:      aws.String("dev"),
+				MethodSettings: map[string]v1types.MethodSetting{"*/*": {LoggingLevel: aws.String("INFO"), DataTraceEnabled: true}},
+			},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_api1/dev"},
+			expectedSummaries: []string{"REST API Access Logs not enabled for api1/dev"},
+			expectedInventory: []stageInfo{
+				{apiId: "api1", stageName: "dev", apiType: RestApiType, executionLogging: ExecutionLoggingFull},
+			},
+		},
+		{
+			name: "rest stage without access log destination",
+			restStage: &v1types.Stage{
+				StageName:         aws.String("dev"),
+				MethodSettings:    map[string]v1types.MethodSetting{"*/*": {LoggingLevel: aws.String("INFO"), DataTraceEnabled: true}},
+				AccessLogSettings: &v1types.AccessLogSettings{Format: format},
+			},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_api1/dev"},
+			expectedSummaries: []string{"REST API Access Logs not enabled for api1/dev"},
+			expectedInventory: []stageInfo{
+				{apiId: "api1", stageName: "dev", apiType: RestApiType, executionLogging: ExecutionLoggingFull},
+			},
+		},
+		{
+			name: "rest stage without access log format",
+			restStage: &v1types.Stage{
+				StageName:         aws.String("dev"),
+				MethodSettings:    map[string]v1types.MethodSetting{"*/*": {LoggingLevel: aws.String("INFO"), DataTraceEnabled: true}},
+				AccessLogSettings: &v1types.AccessLogSettings{DestinationArn: destinationArn},
+			},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_api1/dev"},
+			expectedSummaries: []string{"Access Log Format missing for api1/dev"},
+			expectedInventory: []stageInfo{
+				{apiId: "api1", stageName: "dev", apiType: RestApiType, executionLogging: ExecutionLoggingFull, accessLogGroup: "access-logs"},
+			},
+		},
+		{
+			name: "rest stage with a firehose access log destination",
+			restStage: &v1types.Stage{
+				StageName:      aws.String("dev"),
+				MethodSettings: map[string]v1types.MethodSetting{"*/*": {LoggingLevel: aws.String("INFO"), DataTraceEnabled: true}},
+				AccessLogSettings: &v1types.AccessLogSettings{
+					DestinationArn: aws.String("arn:aws:firehose:us-east-1:123456789012:deliverystream/amazon-apigateway-logs"),
+					Format:         format,
+				},
+			},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_api1/dev"},
+			expectedSummaries: []string{"Access Log destination is not a CloudWatch Logs log group " +
+				"(arn:aws:firehose:us-east-1:123456789012:deliverystream/amazon-apigateway-logs) for api1/dev"},
+			expectedInventory: []stageInfo{
+				{apiId: "api1", stageName: "dev", apiType: RestApiType, executionLogging: ExecutionLoggingFull},
+			},
+		},
+		{
+			name: "rest stage without logging level and access log format",
+			restStage: &v1types.Stage{
+				StageName:         aws.String("dev"),
+				MethodSettings:    map[string]v1types.MethodSetting{"*/*": {}},
+				AccessLogSettings: &v1types.AccessLogSettings{DestinationArn: destinationArn},
+			},
+			expectedSummaries: []string{"Execution Log level missing for api1/dev", "Access Log Format missing for api1/dev"},
+			expectedInventory: []stageInfo{
+				{apiId: "api1", stageName: "dev", apiType: RestApiType, executionLogging: ExecutionLoggingLevelMissing, accessLogGroup: "access-logs"},
+			},
+		},
+		{
+			name: "rest stage without access log format when ignoring access logs",
+			restStage: &v1types.Stage{
+				StageName:         aws.String("dev"),
+				MethodSettings:    map[string]v1types.MethodSetting{"*/*": {LoggingLevel: aws.String("INFO"), DataTraceEnabled: true}},
+				AccessLogSettings: &v1types.AccessLogSettings{DestinationArn: destinationArn},
+			},
+			ignoreAccessLogging: true,
+			expectedLogGroups:   []string{"API-Gateway-Execution-Logs_api1/dev"},
+			expectedInventory: []stageInfo{
+				{apiId: "api1", stageName: "dev", apiType: RestApiType, executionLogging: ExecutionLoggingFull},
+			},
+		},
+		{
+			name:              "http stage without access log settings",
+			httpStage:         &v2types.Stage{StageName: aws.String("$default")},
+			expectedSummaries: []string{"HTTP API Access Logs not enabled for api1/$default"},
+			expectedInventory: []stageInfo{
+				{apiId: "api1", stageName: "$default", apiType: HttpApiType},
+			},
+		},
+		{
+			name: "http stage without access log destination",
+			httpStage: &v2types.Stage{
+				StageName:         aws.String("$default"),
+				AccessLogSettings: &v2types.AccessLogSettings{Format: format},
+			},
+			expectedSummaries: []string{"HTTP API Access Logs not enabled for api1/$default"},
+			expectedInventory: []stageInfo{
+				{apiId: "api1", stageName: "$default", apiType: HttpApiType},
+			},
+		},
+		{
+			name: "http stage without access log format",
+			httpStage: &v2types.Stage{
+				StageName:         aws.String("$default"),
+				AccessLogSettings: &v2types.AccessLogSettings{DestinationArn: destinationArn},
+			},
+			expectedSummaries: []string{"Access Log Format missing for api1/$default"},
+			expectedInventory: []stageInfo{
+				{apiId: "api1", stageName: "$default", apiType: HttpApiType, accessLogGroup: "access-logs"},
+			},
+		},
+		{
+			name: "http stage with a malformed access log destination",
+			httpStage: &v2types.Stage{
+				StageName:         aws.String("$default"),
+				AccessLogSettings: &v2types.AccessLogSettings{DestinationArn: aws.String("access-logs"), Format: format},
+			},
+			expectedSummaries: []string{"Access Log destination is not a CloudWatch Logs log group (access-logs) for api1/$default"},
+			expectedInventory: []stageInfo{
+				{apiId: "api1", stageName: "$default", apiType: HttpApiType},
+			},
+		},
+		{
+			name: "http stage with access log settings",
+			httpStage: &v2types.Stage{
+				StageName:         aws.String("$default"),
+				AccessLogSettings: &v2types.AccessLogSettings{DestinationArn: destinationArn, Format: format},
+			},
+			expectedLogGroups: []string{"access-logs"},
+			expectedInventory: []stageInfo{
+				{apiId: "api1", stageName: "$default", apiType: HttpApiType, accessLogGroup: "access-logs"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn := &fakeApiGatewayProvider{
+				restStages: map[string][]v1types.Stage{},
+				httpStages: map[string][]v2types.Stage{},
+			}
+			if test.restStage != nil {
+				conn.restApis = append(conn.restApis, v1types.RestApi{Id: aws.String("api1")})
+				conn.restStages["api1"] = []v1types.Stage{*test.restStage}
+			}
+			if test.httpStage != nil {
+				conn.httpApis = append(conn.httpApis, v2types.Api{ApiId: aws.String("api1")})
+				conn.httpStages["api1"] = []v2types.Stage{*test.httpStage}
+			}
+			collector := newFindingCollector()
+			stageInventory := &inventory{}
+
+			logGroupNames := getLogGroupNames(context.Background(), newSelection(Selector{ApiList: []string{"api1"}}),
+				test.ignoreAccessLogging, false, false, conn, stageInventory, collector)
+
+			var summaries []string
+			for _, finding := range collector.findings {
+				summaries = append(summaries, finding.Message)
+			}
+			assert.ElementsMatch(t, test.expectedLogGroups, logGroupNames)
+			assert.ElementsMatch(t, test.expectedSummaries, summaries)
+			assert.Equal(t, test.expectedInventory, inventoryStages(stageInventory))
+		})
+	}
+}
+
+// inventoryStages returns the stages of an inventory without the details only used in reports.
+func inventoryStages(stageInventory *inventory) []stageInfo {
+	var stages []stageInfo
+	for _, stage := range stageInventory.stages {
+		stage.accessLogFormat = nil
+		stage.selectedLogGroups = nil
+		stages = append(stages, stage)
+	}
+	return stages
+}
+
+func TestGetLogGroupNamesWebSocketApis(t *testing.T) {
+	destinationArn := aws.String("arn:aws:logs:us-east-1:123456789012:log-group:websocket-access-logs")
+	format := aws.String(`{"domain":"$context.domainName", "status":"$context.status", "route":"$context.routeKey", "event":"$context.eventType", "connection":"$context.connectionId"}`)
+
+	tests := []struct {
+		name              string
+		stage             v2types.Stage
+		expectedLogGroups []string
+		expectedSummaries []string
+		expectedInventory []stageInfo
+	}{
+		{
+			name: "full execution and access logs",
+			stage: v2types.Stage{
+				StageName:            aws.String("prod"),
+				DefaultRouteSettings: &v2types.RouteSettings{LoggingLevel: v2types.LoggingLevelInfo, DataTraceEnabled: aws.Bool(true)},
+				AccessLogSettings:    &v2types.AccessLogSettings{DestinationArn: destinationArn, Format: format},
+			},
+			expectedLogGroups: []string{"/aws/apigateway/ws1/prod", "websocket-access-logs"},
+			expectedInventory: []stageInfo{
+				{apiId: "ws1", stageName: "prod", apiType: WebSocketApiType, executionLogging: ExecutionLoggingFull, accessLogGroup: "websocket-access-logs"},
+			},
+		},
+		{
+			name: "route overrides and http access log format",
+			stage: v2types.Stage{
+				StageName:            aws.String("prod"),
+				DefaultRouteSettings: &v2types.RouteSettings{LoggingLevel: v2types.LoggingLevelInfo, DataTraceEnabled: aws.Bool(true)},
+				RouteSettings: map[string]v2types.RouteSettings{
+					"$connect":    {LoggingLevel: v2types.LoggingLevelInfo, DataTraceEnabled: aws.Bool(true)},
+					"sendMessage": {LoggingLevel: v2types.LoggingLevelOff},
+				},
+				AccessLogSettings: &v2types.AccessLogSettings{
+					DestinationArn: destinationArn,
+					Format:         aws.String(`{"method":"$context.httpMethod", "domain":"$context.domainName", "status":"$context.status", "path":"$context.path"}`),
+				},
+			},
+			expectedLogGroups: []string{"/aws/apigateway/ws1/prod"},
+			expectedSummaries: []string{
+				"Execution Log settings overridden for ws1/prod sendMessage (OFF)",
+				"Access Log Format is missing required values [$context.routeKey, $context.eventType, $context.connectionId] for ws1/prod",
+			},
+			expectedInventory: []stageInfo{
+				{
+					apiId: "ws1", stageName: "prod", apiType: WebSocketApiType, executionLogging: ExecutionLoggingFull, accessLogGroup: "websocket-access-logs",
+					methodOverrides: []methodLoggingSettings{{method: "sendMessage", loggingLevel: "OFF"}},
+				},
+			},
+		},
+		{
+			name:              "no route settings and no access logs",
+			stage:             v2types.Stage{StageName: aws.String("prod")},
+			expectedSummaries: []string{"Execution Logs not configured for ws1/prod", "WebSocket API Access Logs not enabled for ws1/prod"},
+			expectedInventory: []stageInfo{
+				{apiId: "ws1", stageName: "prod", apiType: WebSocketApiType, executionLogging: ExecutionLoggingUnconfigured},
+			},
+		},
+		{
+			name: "errors only",
+			stage: v2types.Stage{
+				StageName:            aws.String("prod"),
+				DefaultRouteSettings: &v2types.RouteSettings{LoggingLevel: v2types.LoggingLevelError},
+				AccessLogSettings:    &v2types.AccessLogSettings{DestinationArn: destinationArn, Format: format},
+			},
+			expectedLogGroups: []string{"websocket-access-logs"},
+			expectedSummaries: []string{"Execution Logs set to Errors Only for ws1/prod"},
+			expectedInventory: []stageInfo{
+				{apiId: "ws1", stageName: "prod", apiType: WebSocketApiType, executionLogging: ExecutionLoggingError, accessLogGroup: "websocket-access-logs"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn := &fakeApiGatewayProvider{
+				httpApis:   []v2types.Api{{ApiId: aws.String("ws1"), ProtocolType: v2types.ProtocolTypeWebsocket}},
+				httpStages: map[string][]v2types.Stage{"ws1": {test.stage}},
+			}
+			collector := newFindingCollector()
+			stageInventory := &inventory{}
+
+			logGroupNames := getLogGroupNames(context.Background(), newSelection(Selector{ApiList: []string{"ws1"}}),
+				false, false, false, conn, stageInventory, collector)
+
+			var summaries []string
+			for _, finding := range collector.findings {
+				summaries = append(summaries, finding.Message)
+			}
+			assert.ElementsMatch(t, test.expectedLogGroups, logGroupNames)
+			assert.ElementsMatch(t, test.expectedSummaries, summaries)
+			assert.Equal(t, test.expectedInventory, inventoryStages(stageInventory))
+		})
+	}
+}
+
+// fakeApiGatewayProvider serves fixed apis and stages in place of the AWS clients
+type fakeApiGatewayProvider struct {
+	restApis   []v1types.RestApi
+	restStages map[string][]v1types.Stage
+	httpApis   []v2types.Api
+	httpStages map[string][]v2types.Stage
+}
+
+var _ AwsApiGatewayProvider = (*fakeApiGatewayProvider)(nil)
+
+func (p *fakeApiGatewayProvider) GetAwsGetRestApisPaginator() AwsGetRestApisPaginator {
+	return v1.NewGetRestApisPaginator(p.GetApiGatewayClient(), &v1.GetRestApisInput{})
+}
+
+func (p *fakeApiGatewayProvider) GetApiGatewayClient() AwsApiGatewayClient {
+	return &fakeApiGatewayClient{p}
+}
+
+func (p *fakeApiGatewayProvider) GetApiGatewayV2Client() AwsApiGatewayV2Client {
+	return &fakeApiGatewayV2Client{p}
+}
+
+type fakeApiGatewayClient struct {
+	*fakeApiGatewayProvider
+}
+
+func (c *fakeApiGatewayClient) GetRestApis(_ context.Context, _ *v1.GetRestApisInput, _ ...func(*v1.Options)) (*v1.GetRestApisOutput, error) {
+	return &v1.GetRestApisOutput{Items: c.restApis}, nil
+}
+
+func (c *fakeApiGatewayClient) GetStages(_ context.Context, params *v1.GetStagesInput, _ ...func(*v1.Options)) (*v1.GetStagesOutput, error) {
+	return &v1.GetStagesOutput{Item: c.restStages[aws.ToString(params.RestApiId)]}, nil
+}
+
+type fakeApiGatewayV2Client struct {
+	*fakeApiGatewayProvider
+}
+
+func (c *fakeApiGatewayV2Client) GetApis(_ context.Context, _ *v2.GetApisInput, _ ...func(*v2.Options)) (*v2.GetApisOutput, error) {
+	return &v2.GetApisOutput{Items: c.httpApis}, nil
+}
+
+func (c *fakeApiGatewayV2Client) GetStages(_ context.Context, params *v2.GetStagesInput, _ ...func(*v2.Options)) (*v2.GetStagesOutput, error) {
+	return &v2.GetStagesOutput{Items: c.httpStages[aws.ToString(params.ApiId)]}, nil
+}
+
+func TestFindingCollector(t *testing.T) {
+	collector := newFindingCollector()
+	sel := newSelection(Selector{ApiList: []string{"api1", "api2/dev", "a/b/c"}})
+	collector.setAccount(findingAccount{Index: 1, AccountId: "123456789012", Region: "us-east-1"}, sel)
+	collector.addError(AccessLogNotEnabledREST, "api1/dev")
+	collector.addError(AccessLogNotEnabledREST, "api2/dev")
+	collector.addSelectorFinding(WrongSyntax, FindingSeverityError, sel.invalid[0])
+	collector.addStageWarn(ExecutionLogMethodOverride, "api1/dev", "api1/dev /pets/GET (ERROR)")
+	collector.setAccount(findingAccount{Index: 2, Region: "eu-west-1"}, newSelection(Selector{ApiList: []string{"api1"}, Exclude: true}))
+	collector.addError(ExecutionLogNotEnabled, "api3/s1")
+
+	consoleUrl := "https://us-east-1.console.aws.amazon.com/apigateway/main/apis/api1/stages?api=api1&region=us-east-1"
+	assert.Equal(t, []Finding{
+		{
+			AccountIndex: 1, AccountId: "123456789012", Region: "us-east-1", ApiId: "api1", StageName: "dev",
+			Code: "access_log_not_enabled_rest", Severity: FindingSeverityError, Summary: string(AccessLogNotEnabledREST),
+			Value: "api1/dev", Message: "REST API Access Logs not enabled for api1/dev", Detail: remediations[AccessLogNotEnabledREST],
+			ApiListIndex: 0, SelectorList: "api_list", ConsoleUrl: consoleUrl,
+		},
+		{
+			AccountIndex: 1, AccountId: "123456789012", Region: "us-east-1", ApiId: "api2", StageName: "dev",
+			Code: "access_log_not_enabled_rest", Severity: FindingSeverityError, Summary: string(AccessLogNotEnabledREST),
+			Value: "api2/dev", Message: "REST API Access Logs not enabled for api2/dev", Detail: remediations[AccessLogNotEnabledREST],
+			ApiListIndex: 1, SelectorList: "api_list", ConsoleUrl: "https://us-east-1.console.aws.amazon.com/apigateway/main/apis/api2/stages?api=api2&region=us-east-1",
+		},
+		{
+			AccountIndex: 1, AccountId: "123456789012", Region: "us-east-1",
+			Code: "wrong_syntax", Severity: FindingSeverityError, Summary: string(WrongSyntax),
+			Value: "a/b/c", Message: "api gateway syntax is wrong for a/b/c", Detail: remediations[WrongSyntax],
+			ApiListIndex: 2, SelectorList: "api_list",
+		},
+		{
+			AccountIndex: 1, AccountId: "123456789012", Region: "us-east-1", ApiId: "api1", StageName: "dev",
+			Code: "execution_log_method_override", Severity: FindingSeverityWarning, Summary: string(ExecutionLogMethodOverride),
+			Value: "api1/dev /pets/GET (ERROR)", Message: "Execution Log settings overridden for api1/dev /pets/GET (ERROR)",
+			Detail: remediations[ExecutionLogMethodOverride], ApiListIndex: 0, SelectorList: "api_list", ConsoleUrl: consoleUrl,
+		},
+		{
+			AccountIndex: 2, Region: "eu-west-1", ApiId: "api3", StageName: "s1",
+			Code: "execution_log_not_enabled", Severity: FindingSeverityError, Summary: string(ExecutionLogNotEnabled),
+			Value: "api3/s1", Message: "Execution Logs not enabled for api3/s1", Detail: remediations[ExecutionLogNotEnabled],
+			ApiListIndex: -1, ConsoleUrl: "https://eu-west-1.console.aws.amazon.com/apigateway/main/apis/api3/stages?api=api3&region=eu-west-1",
+		},
+	}, collector.findings)
+}
+
+func TestFindingSeverity(t *testing.T) {
+	collector := newFindingCollector()
+	collector.setFindingSeverity(map[string]FindingSeverity{
+		"execution_log_not_enabled":      FindingSeverityWarning,
+		"access_log_not_enabled_rest":    FindingSeverityIgnore,
+		"execution_log_method_override":  FindingSeverityError,
+		"access_log_format_key_mismatch": FindingSeverityIgnore,
+	})
+	collector.addError(ExecutionLogNotEnabled, "api1/dev")
+	collector.addError(AccessLogNotEnabledREST, "api1/dev")
+	collector.addStageWarn(ExecutionLogMethodOverride, "api1/dev", "api1/dev /pets/GET (ERROR)")
+	collector.addError(AccessLogFormatNotJson, "api1/dev")
+	collector.addWithDetail(AccessLogFormatKeyMismatchExcluded, FindingSeverityError, "conflicting keys", "detail")
+
+	severities := make(map[string]FindingSeverity)
+	for _, finding := range collector.findings {
+		severities[finding.Message] = finding.Severity
+	}
+	assert.Equal(t, map[string]FindingSeverity{
+		"Execution Logs not enabled for api1/dev":                          FindingSeverityWarning,
+		"REST API Access Logs not enabled for api1/dev":                    FindingSeverityIgnore,
+		"Execution Log settings overridden for api1/dev /pets/GET (ERROR)": FindingSeverityError,
+		"Access Log Format is not JSON parsable for api1/dev":              FindingSeverityError,
+		"conflicting keys": FindingSeverityIgnore,
+	}, severities)
+
+	for summary := range remediations {
+		assert.NotEmpty(t, summary.code(), summary)
+	}
+}
+
+func TestFailOn(t *testing.T) {
+	accounts := []AccountSpec{
+		{Region: "us-east-1", CrossAccountRoleArn: "arn:aws:iam::123456789012:role/traceable"},
+		{Region: "eu-west-1"},
+	}
+	collector := newFindingCollector()
+	collector.setAccount(findingAccount{Index: 0, AccountId: "123456789012", Region: "us-east-1"}, nil)
+	collector.addAccountError("Error while invoking getRestApis sdk call: access denied")
+	collector.addAccountError("Error while invoking getApis sdk call: access denied")
+
+	assert.Equal(t, []FailedAccount{{
+		AccountIndex:        0,
+		Region:              "us-east-1",
+		AccountId:           "123456789012",
+		CrossAccountRoleArn: "arn:aws:iam::123456789012:role/traceable",
+		Error:               "Error while invoking getRestApis sdk call: access denied; Error while invoking getApis sdk call: access denied",
+	}}, collector.failedAccounts(accounts))
+
+	tests := []struct {
+		failOn         FailOn
+		failedAccounts int
+		fails          bool
+	}{
+		{failOn: FailOnAnyError, failedAccounts: 0, fails: false},
+		{failOn: FailOnAnyError, failedAccounts: 1, fails: true},
+		{failOn: FailOnAllAccountsFailed, failedAccounts: 1, fails: false},
+		{failOn: FailOnAllAccountsFailed, failedAccounts: 2, fails: true},
+		{failOn: FailOnNever, failedAccounts: 2, fails: false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.fails, test.failOn.Fails(test.failedAccounts, len(accounts)), "%s with %d failed accounts", test.failOn, test.failedAccounts)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	fake := &fakeApiGatewayProvider{
+		restApis: []v1types.RestApi{{Id: aws.String("api1")}, {Id: aws.String("api2")}},
+		restStages: map[string][]v1types.Stage{
+			"api1": {{
+				StageName:      aws.String("dev"),
+				MethodSettings: map[string]v1types.MethodSetting{"*/*": {LoggingLevel: aws.String("INFO"), DataTraceEnabled: true}},
+				AccessLogSettings: &v1types.AccessLogSettings{
+					DestinationArn: aws.String("arn:aws:logs:us-east-1:123456789012:log-group:access-logs"),
+					Format:         aws.String(`{"method":"$context.httpMethod", "domain":"$context.domainName", "status":"$context.status", "path":"$context.path"}`),
+				},
+			}},
+			"api2": {{StageName: aws.String("prod")}},
+		},
+	}
+	var regions []string
+	opts := Options{
+		Timeout: time.Minute,
+		ClientFactory: func(_ context.Context, account AccountSpec) (*Clients, error) {
+			regions = append(regions, account.Region)
+			return &Clients{ApiGateway: &fakeApiGatewayClient{fake}, ApiGatewayV2: &fakeApiGatewayV2Client{fake}}, nil
+		},
+	}
+
+	result, err := Discover(context.Background(), []AccountSpec{
+		{Region: "us-east-1", Selector: Selector{ApiList: []string{"api1"}}},
+		{Region: "eu-west-1", Selector: Selector{ApiList: []string{"api1"}, Exclude: true}},
+	}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"us-east-1", "eu-west-1"}, regions)
+	assert.Equal(t, []string{"API-Gateway-Execution-Logs_api1/dev", "access-logs"}, result.LogGroupNames)
+	var stages []string
+	for _, stage := range result.Stages {
+		stages = append(stages, fmt.Sprintf("%d %s/%s %s", stage.AccountIndex, stage.ApiId, stage.StageName, stage.Status))
+	}
+	assert.Equal(t, []string{"0 api1/dev passed", "1 api2/prod failed"}, stages)
+	assert.True(t, result.HasErrors())
+	assert.Empty(t, result.FailedAccounts)
+	var selections []string
+	for _, selection := range result.Selections {
+		selections = append(selections, fmt.Sprintf("%d %s", selection.AccountIndex, selection))
+	}
+	assert.Equal(t, []string{
+		`0 api1/dev: included by api_list[0] "api1"`,
+		"0 api2: excluded, no entry of api_list matches",
+		`1 api1: excluded by api_list[0] "api1"`,
+		"1 api2/prod: included, no entry of api_list matches",
+	}, selections)
+
+	result, err = Discover(context.Background(), []AccountSpec{
+		{Region: "us-east-1", Selector: Selector{IncludeApis: []string{"*"}, ExcludeApis: []string{"api2/prod"}}},
+	}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"API-Gateway-Execution-Logs_api1/dev", "access-logs"}, result.LogGroupNames)
+
+	// entries matching nothing are warnings, or errors with FailOnUnmatched
+	for _, failOnUnmatched := range []bool{false, true} {
+		opts.FailOnUnmatched = failOnUnmatched
+		result, err = Discover(context.Background(), []AccountSpec{
+			{Region: "us-east-1", Selector: Selector{IncludeApis: []string{"api1", "api3"}}},
+		}, opts)
+		assert.NoError(t, err)
+		assert.Len(t, result.Findings, 1)
+		assert.Equal(t, "selector_unmatched", result.Findings[0].Code)
+		assert.Equal(t, "include_apis", result.Findings[0].SelectorList)
+		assert.Equal(t, 1, result.Findings[0].ApiListIndex)
+		assert.Equal(t, failOnUnmatched, result.HasErrors())
+	}
+	opts.FailOnUnmatched = false
+
+	_, err = Discover(context.Background(), []AccountSpec{{Region: "us-east-1", Selector: Selector{Exclude: true}}, {}}, opts)
+	var specErr *SpecError
+	assert.ErrorAs(t, err, &specErr)
+	assert.Equal(t, 1, specErr.Index)
+
+	_, err = Discover(context.Background(), []AccountSpec{
+		{Region: "us-east-1", Selector: Selector{ApiList: []string{"api1"}, ExcludeApis: []string{"api2"}}},
+	}, opts)
+	assert.ErrorAs(t, err, &specErr)
+	assert.Equal(t, "api_list and exclude can't be combined with include_apis and exclude_apis", specErr.Message)
+
+	_, err = Discover(context.Background(), []AccountSpec{{Region: "us-east-1"}}, opts)
+	assert.ErrorAs(t, err, &specErr)
+	assert.Equal(t, "include_apis or exclude_apis must be set", specErr.Message)
+
+	// roles must be in the partition of the account, which the options may override
+	govAccount := AccountSpec{Region: "us-gov-west-1", CrossAccountRoleArn: "arn:aws:iam::123456789012:role/traceable", Selector: Selector{IncludeApis: []string{"api1"}}}
+	_, err = Discover(context.Background(), []AccountSpec{govAccount}, opts)
+	assert.ErrorAs(t, err, &specErr)
+	assert.Equal(t, "cross_account_role_arn is in the aws partition, the account is in aws-us-gov, such as arn:aws-us-gov:iam::123456789012:role/traceable", specErr.Message)
+	opts.Partition = PartitionAws
+	_, err = Discover(context.Background(), []AccountSpec{govAccount}, opts)
+	assert.NoError(t, err)
+	opts.Partition = "aws-eu"
+	_, err = Discover(context.Background(), []AccountSpec{govAccount}, opts)
+	assert.EqualError(t, err, `partition "aws-eu" is not one of [aws, aws-cn, aws-us-gov]`)
+	opts.Partition = ""
+
+	// so must the STS region, the one of the options applies to accounts without one
+	opts.StsRegion = "us-east-1"
+	_, err = Discover(context.Background(), []AccountSpec{{Region: "us-gov-west-1", Selector: Selector{IncludeApis: []string{"api1"}}}}, opts)
+	assert.ErrorAs(t, err, &specErr)
+	assert.Equal(t, "sts_region us-east-1 is in the aws partition, the account is in aws-us-gov", specErr.Message)
+	var stsRegions []string
+	opts.ClientFactory = func(_ context.Context, account AccountSpec) (*Clients, error) {
+		stsRegions = append(stsRegions, account.StsRegion)
+		return nil, errors.New("no credentials")
+	}
+	_, err = Discover(context.Background(), []AccountSpec{
+		{Region: "ap-east-1", Selector: Selector{IncludeApis: []string{"api1"}}},
+		{Region: "ap-east-1", StsRegion: "ap-southeast-1", Selector: Selector{IncludeApis: []string{"api1"}}},
+	}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"us-east-1", "ap-southeast-1"}, stsRegions)
+	opts.StsRegion = ""
+
+	opts.ClientFactory = func(context.Context, AccountSpec) (*Clients, error) {
+		return nil, errors.New("no credentials")
+	}
+	result, err = Discover(context.Background(), []AccountSpec{{Region: "us-east-1", Selector: Selector{ApiList: []string{"api1"}}}}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []FailedAccount{{Region: "us-east-1", Error: "no credentials"}}, result.FailedAccounts)
+}
+
+func TestClientFactory(t *testing.T) {
+	// a fake STS recording the region of each request
+	var assumeRoleRegions, callerIdentityRegions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		// AWS4-HMAC-SHA256 Credential=AKID/20240101/us-east-1/sts/aws4_request, ...
+		region := strings.Split(r.Header.Get("Authorization"), "/")[2]
+		w.Header().Set("Content-Type", "text/xml")
+		if r.PostForm.Get("Action") == "AssumeRole" {
+			assumeRoleRegions = append(assumeRoleRegions, region)
+			fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleResult>
+<Credentials><AccessKeyId>ASIAROLE</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken>
+<Expiration>%s</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+			return
+		}
+		callerIdentityRegions = append(callerIdentityRegions, region)
+		fmt.Fprint(w, `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><GetCallerIdentityResult>
+<Account>123456789012</Account></GetCallerIdentityResult></GetCallerIdentityResponse>`)
+	}))
+	defer server.Close()
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAFACTORY")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+
+	factory := NewClientFactory(Endpoints{Sts: server.URL})
+	role := "arn:aws:iam::123456789012:role/traceable"
+	accounts := []AccountSpec{
+		{Region: "us-east-1", CrossAccountRoleArn: role, StsRegion: "us-east-1"},
+		{Region: "ap-east-1", CrossAccountRoleArn: role, StsRegion: "us-east-1"},
+		{Region: "eu-west-1", CrossAccountRoleArn: role},
+		{Region: "eu-west-1"},
+	}
+	for _, account := range accounts {
+		clients, err := factory(context.Background(), account)
+		assert.NoError(t, err)
+		assert.Equal(t, "123456789012", getAccountId(context.Background(), clients.Sts, account.CrossAccountRoleArn))
+	}
+	// the accounts sharing the role and the STS region share its credentials
+	assert.Equal(t, []string{"us-east-1", "eu-west-1"}, assumeRoleRegions)
+	assert.Equal(t, []string{"us-east-1", "us-east-1", "eu-west-1", "eu-west-1"}, callerIdentityRegions)
+}
+
+func TestDiscoverReplay(t *testing.T) {
+	// recorded against provider/testdata/fakeaws.yaml, whose accounts 123456789012 and
+	// 210987654321 are anonymized to 000000920743 and 000000501103
+	t.Setenv(ReplayCassetteEnv, "testdata/cassettes/two-accounts.yaml")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+	apiId := func(apiId string) string { return cassette.Anonymize(cassette.KindApiId, apiId) }
+	dev := cassette.Anonymize(cassette.KindStageName, "dev")
+
+	result, err := Discover(context.Background(), []AccountSpec{
+		{Region: "us-east-1", Selector: Selector{IncludeApis: []string{cassette.Anonymize(cassette.KindApiName, "pets"), apiId("rest2"), apiId("http1")}}},
+		{Region: "eu-west-1", CrossAccountRoleArn: "arn:aws:iam::000000501103:role/" + cassette.Anonymize(cassette.KindRoleName, "audit"), Selector: Selector{Exclude: true}},
+	}, Options{})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"API-Gateway-Execution-Logs_" + apiId("rest1") + "/" + dev,
+		cassette.Anonymize(cassette.KindLogGroup, "orders-access-logs"),
+		cassette.Anonymize(cassette.KindLogGroup, "pets-access-logs"),
+	}, result.LogGroupNames)
+	var stages []string
+	for _, stage := range result.Stages {
+		stages = append(stages, fmt.Sprintf("%s %s/%s %s", stage.AccountId, stage.ApiId, stage.StageName, stage.Status))
+	}
+	assert.ElementsMatch(t, []string{
+		"000000920743 " + apiId("rest1") + "/" + dev + " passed",
+		"000000920743 " + apiId("rest2") + "/" + dev + " failed",
+		"000000920743 " + apiId("http1") + "/$default passed",
+	}, stages)
+	assert.Len(t, result.FailedAccounts, 1)
+	assert.Equal(t, "000000501103", result.FailedAccounts[0].AccountId)
+	assert.Contains(t, result.FailedAccounts[0].Error, "AccessDeniedException: User is not authorized to perform apigateway:GET")
+
+	// requests that were not recorded fail instead of reaching AWS
+	result, err = Discover(context.Background(), []AccountSpec{{Region: "ap-south-1", Selector: Selector{Exclude: true}}}, Options{})
+	assert.NoError(t, err)
+	assert.Len(t, result.FailedAccounts, 1)
+	assert.Contains(t, result.FailedAccounts[0].Error, "testdata/cassettes/two-accounts.yaml has no response to apigateway GET /restapis in ap-south-1")
+}
@@ -0,0 +1,23 @@
+package discovery
+
+import "context"
+
+// GetLogGroupNames runs getLogGroupNames on a single account for the tests of package
+// discovery_test, which can use the generated mocks without an import cycle. It returns
+// the log groups along with the messages of the findings and the account errors.
+func GetLogGroupNames(ctx context.Context, apiList []string, exclude bool, ignoreAccessLogSettings bool,
+	strictLogGroupFormat bool, conn AwsApiGatewayProvider) ([]string, []string, []string) {
+	collector := newFindingCollector()
+	sel := newSelection(Selector{ApiList: apiList, Exclude: exclude})
+	collector.setAccount(findingAccount{Index: 0}, sel)
+	logGroupNames := getLogGroupNames(ctx, sel, ignoreAccessLogSettings, strictLogGroupFormat, false, conn, &inventory{}, collector)
+
+	var findings, accountErrors []string
+	for _, finding := range collector.findings {
+		findings = append(findings, finding.Message)
+	}
+	for _, accountError := range collector.accountErrors {
+		accountErrors = append(accountErrors, accountError.message)
+	}
+	return logGroupNames, findings, accountErrors
+}
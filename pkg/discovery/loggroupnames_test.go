@@ -0,0 +1,471 @@
+package discovery_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery/mocks"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v1 "github.com/aws/aws-sdk-go-v2/service/apigateway"
+	v1types "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
+	v2 "github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	v2types "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const validFormat = `{"method":"$context.httpMethod","domain":"$context.domainName","status":"$context.status","path":"$context.path"}`
+
+func restStage(name string, logGroup string, format string) v1types.Stage {
+	stage := v1types.Stage{
+		StageName:      aws.String(name),
+		MethodSettings: map[string]v1types.MethodSetting{"*/*": {LoggingLevel: aws.String("INFO"), DataTraceEnabled: true}},
+	}
+	if logGroup != "" {
+		stage.AccessLogSettings = &v1types.AccessLogSettings{
+			DestinationArn: aws.String("arn:aws:logs:us-east-1:123456789012:log-group:" + logGroup),
+			Format:         aws.String(format),
+		}
+	}
+	return stage
+}
+
+func httpStage(name string, logGroup string, format string) v2types.Stage {
+	return v2types.Stage{
+		StageName: aws.String(name),
+		AccessLogSettings: &v2types.AccessLogSettings{
+			DestinationArn: aws.String("arn:aws:logs:us-east-1:123456789012:log-group:" + logGroup),
+			Format:         aws.String(format),
+		},
+	}
+}
+
+func restApis(ids ...string) []v1types.RestApi {
+	var apis []v1types.RestApi
+	for _, id := range ids {
+		apis = append(apis, v1types.RestApi{Id: aws.String(id)})
+	}
+	return apis
+}
+
+var (
+	defaultRestPages  = [][]v1types.RestApi{restApis("rest1", "rest2")}
+	defaultRestStages = map[string][]v1types.Stage{
+		"rest1": {restStage("dev", "rest1-dev", validFormat), restStage("prod", "rest1-prod", validFormat)},
+		"rest2": {restStage("dev", "rest2-dev", validFormat)},
+	}
+	defaultHttpApis   = []v2types.Api{{ApiId: aws.String("http1"), ProtocolType: v2types.ProtocolTypeHttp}}
+	defaultHttpStages = map[string][]v2types.Stage{
+		"http1": {httpStage("$default", "http1-default", validFormat)},
+	}
+)
+
+func TestGetLogGroupNames(t *testing.T) {
+	tests := []struct {
+		name                    string
+		apiList                 []string
+		exclude                 bool
+		ignoreAccessLogSettings bool
+		strictLogGroupFormat    bool
+		// restPages are the pages of GetRestApis, restPagesErr fails the page after them
+		restPages             [][]v1types.RestApi
+		restPagesErr          error
+		restStages            map[string][]v1types.Stage
+		httpApis              []v2types.Api
+		httpApisErr           error
+		httpStages            map[string][]v2types.Stage
+		expectedLogGroups     []string
+		expectedFindings      []string
+		expectedAccountErrors []string
+	}{
+		{
+			name:    "include apis",
+			apiList: []string{"rest1", "http1"},
+			expectedLogGroups: []string{
+				"API-Gateway-Execution-Logs_rest1/dev", "rest1-dev",
+				"API-Gateway-Execution-Logs_rest1/prod", "rest1-prod",
+				"http1-default",
+			},
+		},
+		{
+			name:              "include a stage",
+			apiList:           []string{"rest1/dev"},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_rest1/dev", "rest1-dev"},
+		},
+		{
+			name:    "api entry wins over its stages",
+			apiList: []string{"rest1/dev", "rest1"},
+			expectedLogGroups: []string{
+				"API-Gateway-Execution-Logs_rest1/dev", "rest1-dev",
+				"API-Gateway-Execution-Logs_rest1/prod", "rest1-prod",
+			},
+		},
+		{
+			name:              "unknown apis select nothing",
+			apiList:           []string{"unknown", "unknown/dev"},
+			expectedLogGroups: []string{},
+			expectedFindings: []string{
+				"api selector matches no API or stage for unknown",
+				"api selector matches no API or stage for unknown/dev",
+			},
+		},
+		{
+			name:              "unknown stage of an api",
+			apiList:           []string{"rest1/test", "http1"},
+			expectedLogGroups: []string{"http1-default"},
+			expectedFindings:  []string{"api selector matches no API or stage for rest1/test"},
+		},
+		{
+			name:    "stages of excluded apis are not listed",
+			apiList: []string{"rest1", "rest1/dev", "unknown"},
+			exclude: true,
+			expectedLogGroups: []string{
+				"API-Gateway-Execution-Logs_rest2/dev", "rest2-dev",
+				"http1-default",
+			},
+			expectedFindings: []string{"api selector matches no API or stage for unknown"},
+		},
+		{
+			name:    "exclude apis",
+			apiList: []string{"rest1"},
+			exclude: true,
+			expectedLogGroups: []string{
+				"API-Gateway-Execution-Logs_rest2/dev", "rest2-dev",
+				"http1-default",
+			},
+		},
+		{
+			name:    "exclude a stage",
+			apiList: []string{"rest1/dev"},
+			exclude: true,
+			expectedLogGroups: []string{
+				"API-Gateway-Execution-Logs_rest1/prod", "rest1-prod",
+				"API-Gateway-Execution-Logs_rest2/dev", "rest2-dev",
+				"http1-default",
+			},
+		},
+		{
+			name:              "wrong syntax",
+			apiList:           []string{"rest1/dev/extra", "rest2"},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_rest2/dev", "rest2-dev"},
+			expectedFindings:  []string{"api gateway syntax is wrong for rest1/dev/extra"},
+		},
+		{
+			name:              "apis over several pages",
+			apiList:           []string{"rest2"},
+			restPages:         [][]v1types.RestApi{restApis("rest1"), restApis("rest2")},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_rest2/dev", "rest2-dev"},
+		},
+		{
+			name:                  "pagination error",
+			apiList:               []string{"rest1", "rest2", "http1"},
+			restPages:             [][]v1types.RestApi{restApis("rest1")},
+			restPagesErr:          errors.New("throttled"),
+			expectedLogGroups:     []string{"http1-default"},
+			expectedAccountErrors: []string{"Error while invoking getRestApis sdk call: throttled"},
+		},
+		{
+			name:                  "get apis error",
+			apiList:               []string{"rest2", "http1"},
+			httpApisErr:           errors.New("access denied"),
+			expectedLogGroups:     []string{"API-Gateway-Execution-Logs_rest2/dev", "rest2-dev"},
+			expectedAccountErrors: []string{"Error while invoking getApis sdk call: access denied"},
+		},
+		{
+			name:                  "get stages error",
+			apiList:               []string{"rest3"},
+			restPages:             [][]v1types.RestApi{restApis("rest3")},
+			expectedLogGroups:     []string{},
+			expectedAccountErrors: []string{"Error while invoking getStages sdk call: no stages for rest3"},
+		},
+		{
+			name:    "format is not json",
+			apiList: []string{"rest1/dev"},
+			restStages: map[string][]v1types.Stage{
+				"rest1": {restStage("dev", "rest1-dev", `$context.requestId $context.status`)},
+			},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_rest1/dev"},
+			expectedFindings:  []string{"Access Log Format is not JSON parsable for rest1/dev"},
+		},
+		{
+			name:    "format with unquoted variables",
+			apiList: []string{"rest1/dev"},
+			restStages: map[string][]v1types.Stage{
+				"rest1": {restStage("dev", "rest1-dev", `{"method":$context.httpMethod,"domain":"$context.domainName","status":$context.status,"path":"$context.path"}`)},
+			},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_rest1/dev", "rest1-dev"},
+		},
+		{
+			name:    "format missing required values",
+			apiList: []string{"rest1/dev"},
+			restStages: map[string][]v1types.Stage{
+				"rest1": {restStage("dev", "rest1-dev", `{"method":"$context.httpMethod","status":"$context.status"}`)},
+			},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_rest1/dev"},
+			expectedFindings:  []string{"Access Log Format is missing required values [$context.domainName, $context.path] for rest1/dev"},
+		},
+		{
+			name:    "access logs not enabled",
+			apiList: []string{"rest1/dev"},
+			restStages: map[string][]v1types.Stage{
+				"rest1": {restStage("dev", "", "")},
+			},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_rest1/dev"},
+			expectedFindings:  []string{"REST API Access Logs not enabled for rest1/dev"},
+		},
+		{
+			name:                    "ignore access log settings",
+			apiList:                 []string{"rest1/dev", "http1"},
+			ignoreAccessLogSettings: true,
+			restStages: map[string][]v1types.Stage{
+				"rest1": {restStage("dev", "", "")},
+			},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_rest1/dev"},
+		},
+		{
+			name:    "conflicting keys in a log group",
+			apiList: []string{"rest1"},
+			restStages: map[string][]v1types.Stage{
+				"rest1": {
+					restStage("dev", "shared", validFormat),
+					restStage("prod", "shared", `{"httpMethod":"$context.httpMethod","domain":"$context.domainName","status":"$context.status","path":"$context.path"}`),
+				},
+			},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_rest1/dev", "API-Gateway-Execution-Logs_rest1/prod", "shared"},
+			expectedFindings:  []string{"Access Log Format has conflicting keys in log group shared for [$context.httpMethod]"},
+		},
+		{
+			name:                 "conflicting keys in a log group in strict mode",
+			apiList:              []string{"rest1"},
+			strictLogGroupFormat: true,
+			restStages: map[string][]v1types.Stage{
+				"rest1": {
+					restStage("dev", "shared", validFormat),
+					restStage("prod", "shared", `{"httpMethod":"$context.httpMethod","domain":"$context.domainName","status":"$context.status","path":"$context.path"}`),
+				},
+			},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_rest1/dev", "API-Gateway-Execution-Logs_rest1/prod"},
+			expectedFindings:  []string{"Access Log Format has conflicting keys, log group excluded in log group shared for [$context.httpMethod]"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.restPages == nil {
+				test.restPages = defaultRestPages
+			}
+			if test.restStages == nil {
+				test.restStages = defaultRestStages
+			}
+			if test.httpApis == nil {
+				test.httpApis = defaultHttpApis
+			}
+			if test.httpStages == nil {
+				test.httpStages = defaultHttpStages
+			}
+			conn := newMockProvider(t, test.restPages, test.restPagesErr, test.restStages, test.httpApis, test.httpApisErr, test.httpStages)
+
+			logGroupNames, findings, accountErrors := discovery.GetLogGroupNames(context.Background(), test.apiList, test.exclude,
+				test.ignoreAccessLogSettings, test.strictLogGroupFormat, conn)
+			assert.ElementsMatch(t, test.expectedLogGroups, logGroupNames)
+			assert.ElementsMatch(t, test.expectedFindings, findings)
+			assert.ElementsMatch(t, test.expectedAccountErrors, accountErrors)
+		})
+	}
+}
+
+// newMockProvider serves apis and stages through the generated mocks, stages of apis
+// without an entry fail.
+func newMockProvider(t *testing.T, restPages [][]v1types.RestApi, restPagesErr error, restStages map[string][]v1types.Stage,
+	httpApis []v2types.Api, httpApisErr error, httpStages map[string][]v2types.Stage) discovery.AwsApiGatewayProvider {
+	ctrl := gomock.NewController(t)
+
+	page := 0
+	paginator := mocks.NewMockAwsGetRestApisPaginator(ctrl)
+	paginator.EXPECT().HasMorePages().DoAndReturn(func() bool {
+		return page < len(restPages) || (restPagesErr != nil && page == len(restPages))
+	}).AnyTimes()
+	paginator.EXPECT().NextPage(gomock.Any()).DoAndReturn(func(_ context.Context, _ ...func(*v1.Options)) (*v1.GetRestApisOutput, error) {
+		page++
+		if page > len(restPages) {
+			return nil, restPagesErr
+		}
+		return &v1.GetRestApisOutput{Items: restPages[page-1]}, nil
+	}).AnyTimes()
+
+	apiGatewayClient := mocks.NewMockAwsApiGatewayClient(ctrl)
+	apiGatewayClient.EXPECT().GetStages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params *v1.GetStagesInput, _ ...func(*v1.Options)) (*v1.GetStagesOutput, error) {
+			stages, found := restStages[aws.ToString(params.RestApiId)]
+			if !found {
+				return nil, errors.New("no stages for " + aws.ToString(params.RestApiId))
+			}
+			return &v1.GetStagesOutput{Item: stages}, nil
+		}).AnyTimes()
+
+	apiGatewayV2Client := mocks.NewMockAwsApiGatewayV2Client(ctrl)
+	apiGatewayV2Client.EXPECT().GetApis(gomock.Any(), gomock.Any()).Return(&v2.GetApisOutput{Items: httpApis}, httpApisErr).AnyTimes()
+	apiGatewayV2Client.EXPECT().GetStages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params *v2.GetStagesInput, _ ...func(*v2.Options)) (*v2.GetStagesOutput, error) {
+			stages, found := httpStages[aws.ToString(params.ApiId)]
+			if !found {
+				return nil, errors.New("no stages for " + aws.ToString(params.ApiId))
+			}
+			return &v2.GetStagesOutput{Items: stages}, nil
+		}).AnyTimes()
+
+	conn := mocks.NewMockAwsApiGatewayProvider(ctrl)
+	conn.EXPECT().GetAwsGetRestApisPaginator().Return(paginator).AnyTimes()
+	conn.EXPECT().GetApiGatewayClient().Return(apiGatewayClient).AnyTimes()
+	conn.EXPECT().GetApiGatewayV2Client().Return(apiGatewayV2Client).AnyTimes()
+	return conn
+}
+
+// Fields the SDK leaves nil are reported, a stage without access log format or destination
+// still has its execution logs checked.
+func TestGetLogGroupNamesNilFields(t *testing.T) {
+	methodSettings := map[string]v1types.MethodSetting{"*/*": {LoggingLevel: aws.String("INFO"), DataTraceEnabled: true}}
+	destinationArn := aws.String("arn:aws:logs:us-east-1:123456789012:log-group:access-logs")
+	tests := []struct {
+		name                  string
+		restApis              []v1types.RestApi
+		restStages            map[string][]v1types.Stage
+		httpApis              []v2types.Api
+		httpStages            map[string][]v2types.Stage
+		expectedLogGroups     []string
+		expectedFindings      []string
+		expectedAccountErrors []string
+	}{
+		{
+			name:                  "rest api id",
+			restApis:              []v1types.RestApi{{Name: aws.String("pets")}},
+			expectedLogGroups:     []string{},
+			expectedAccountErrors: []string{"getRestApis returned an API without an ID"},
+		},
+		{
+			name:                  "http api id",
+			httpApis:              []v2types.Api{{Name: aws.String("orders"), ProtocolType: v2types.ProtocolTypeHttp}},
+			expectedLogGroups:     []string{},
+			expectedAccountErrors: []string{"getApis returned an API without an ID"},
+		},
+		{
+			name:                  "rest stage name",
+			restApis:              restApis("rest1"),
+			restStages:            map[string][]v1types.Stage{"rest1": {{MethodSettings: methodSettings}}},
+			expectedLogGroups:     []string{},
+			expectedAccountErrors: []string{"getStages returned a stage without a name for rest1"},
+		},
+		{
+			name:                  "http stage name",
+			httpApis:              []v2types.Api{{ApiId: aws.String("http1"), ProtocolType: v2types.ProtocolTypeHttp}},
+			httpStages:            map[string][]v2types.Stage{"http1": {{}}},
+			expectedLogGroups:     []string{},
+			expectedAccountErrors: []string{"getStages returned a stage without a name for http1"},
+		},
+		{
+			name:     "rest access log settings",
+			restApis: restApis("rest1"),
+			restStages: map[string][]v1types.Stage{"rest1": {{
+				StageName:      aws.String("dev"),
+				MethodSettings: methodSettings,
+			}}},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_rest1/dev"},
+			expectedFindings:  []string{"REST API Access Logs not enabled for rest1/dev"},
+		},
+		{
+			name:     "rest access log destination",
+			restApis: restApis("rest1"),
+			restStages: map[string][]v1types.Stage{"rest1": {{
+				StageName:         aws.String("dev"),
+				MethodSettings:    methodSettings,
+				AccessLogSettings: &v1types.AccessLogSettings{Format: aws.String(validFormat)},
+			}}},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_rest1/dev"},
+			expectedFindings:  []string{"REST API Access Logs not enabled for rest1/dev"},
+		},
+		{
+			name:     "rest access log format",
+			restApis: restApis("rest1"),
+			restStages: map[string][]v1types.Stage{"rest1": {{
+				StageName:         aws.String("dev"),
+				MethodSettings:    methodSettings,
+				AccessLogSettings: &v1types.AccessLogSettings{DestinationArn: destinationArn},
+			}}},
+			expectedLogGroups: []string{"API-Gateway-Execution-Logs_rest1/dev"},
+			expectedFindings:  []string{"Access Log Format missing for rest1/dev"},
+		},
+		{
+			name:     "http access log destination",
+			httpApis: []v2types.Api{{ApiId: aws.String("http1"), ProtocolType: v2types.ProtocolTypeHttp}},
+			httpStages: map[string][]v2types.Stage{"http1": {{
+				StageName:         aws.String("$default"),
+				AccessLogSettings: &v2types.AccessLogSettings{Format: aws.String(validFormat)},
+			}}},
+			expectedLogGroups: []string{},
+			expectedFindings:  []string{"HTTP API Access Logs not enabled for http1/$default"},
+		},
+		{
+			name:     "http access log format",
+			httpApis: []v2types.Api{{ApiId: aws.String("http1"), ProtocolType: v2types.ProtocolTypeHttp}},
+			httpStages: map[string][]v2types.Stage{"http1": {{
+				StageName:         aws.String("$default"),
+				AccessLogSettings: &v2types.AccessLogSettings{DestinationArn: destinationArn},
+			}}},
+			expectedLogGroups: []string{},
+			expectedFindings:  []string{"Access Log Format missing for http1/$default"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var restPages [][]v1types.RestApi
+			if test.restApis != nil {
+				restPages = [][]v1types.RestApi{test.restApis}
+			}
+			conn := newMockProvider(t, restPages, nil, test.restStages, test.httpApis, nil, test.httpStages)
+			logGroupNames, findings, accountErrors := discovery.GetLogGroupNames(context.Background(), nil, true, false, false, conn)
+			assert.ElementsMatch(t, test.expectedLogGroups, logGroupNames)
+			assert.ElementsMatch(t, test.expectedFindings, findings)
+			assert.ElementsMatch(t, test.expectedAccountErrors, accountErrors)
+		})
+	}
+}
+
+// apigatewayv2 has no paginator, the apis and stages of every page are read until
+// NextToken is empty.
+func TestGetLogGroupNamesV2Pages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paginator := mocks.NewMockAwsGetRestApisPaginator(ctrl)
+	paginator.EXPECT().HasMorePages().Return(false).AnyTimes()
+
+	apiGatewayV2Client := mocks.NewMockAwsApiGatewayV2Client(ctrl)
+	apiGatewayV2Client.EXPECT().GetApis(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params *v2.GetApisInput, _ ...func(*v2.Options)) (*v2.GetApisOutput, error) {
+			if aws.ToString(params.NextToken) == "" {
+				return &v2.GetApisOutput{
+					Items:     []v2types.Api{{ApiId: aws.String("http1"), ProtocolType: v2types.ProtocolTypeHttp}},
+					NextToken: aws.String("1"),
+				}, nil
+			}
+			return &v2.GetApisOutput{Items: []v2types.Api{{ApiId: aws.String("http2"), ProtocolType: v2types.ProtocolTypeHttp}}}, nil
+		}).Times(2)
+	apiGatewayV2Client.EXPECT().GetStages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params *v2.GetStagesInput, _ ...func(*v2.Options)) (*v2.GetStagesOutput, error) {
+			apiId := aws.ToString(params.ApiId)
+			if aws.ToString(params.NextToken) == "" {
+				return &v2.GetStagesOutput{Items: []v2types.Stage{httpStage("dev", apiId+"-dev", validFormat)}, NextToken: aws.String("1")}, nil
+			}
+			return &v2.GetStagesOutput{Items: []v2types.Stage{httpStage("prod", apiId+"-prod", validFormat)}}, nil
+		}).Times(4)
+
+	conn := mocks.NewMockAwsApiGatewayProvider(ctrl)
+	conn.EXPECT().GetAwsGetRestApisPaginator().Return(paginator).AnyTimes()
+	conn.EXPECT().GetApiGatewayClient().Return(mocks.NewMockAwsApiGatewayClient(ctrl)).AnyTimes()
+	conn.EXPECT().GetApiGatewayV2Client().Return(apiGatewayV2Client).AnyTimes()
+	logGroupNames, findings, accountErrors := discovery.GetLogGroupNames(context.Background(), nil, true, false, false, conn)
+	assert.ElementsMatch(t, []string{"http1-dev", "http1-prod", "http2-dev", "http2-prod"}, logGroupNames)
+	assert.Empty(t, findings)
+	assert.Empty(t, accountErrors)
+}
@@ -0,0 +1,76 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery (interfaces: AwsApiGatewayClient)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	apigateway "github.com/aws/aws-sdk-go-v2/service/apigateway"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAwsApiGatewayClient is a mock of AwsApiGatewayClient interface.
+type MockAwsApiGatewayClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAwsApiGatewayClientMockRecorder
+}
+
+// MockAwsApiGatewayClientMockRecorder is the mock recorder for MockAwsApiGatewayClient.
+type MockAwsApiGatewayClientMockRecorder struct {
+	mock *MockAwsApiGatewayClient
+}
+
+// NewMockAwsApiGatewayClient creates a new mock instance.
+func NewMockAwsApiGatewayClient(ctrl *gomock.Controller) *MockAwsApiGatewayClient {
+	mock := &MockAwsApiGatewayClient{ctrl: ctrl}
+	mock.recorder = &MockAwsApiGatewayClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAwsApiGatewayClient) EXPECT() *MockAwsApiGatewayClientMockRecorder {
+	return m.recorder
+}
+
+// GetRestApis mocks base method.
+func (m *MockAwsApiGatewayClient) GetRestApis(arg0 context.Context, arg1 *apigateway.GetRestApisInput, arg2 ...func(*apigateway.Options)) (*apigateway.GetRestApisOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetRestApis", varargs...)
+	ret0, _ := ret[0].(*apigateway.GetRestApisOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRestApis indicates an expected call of GetRestApis.
+func (mr *MockAwsApiGatewayClientMockRecorder) GetRestApis(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRestApis", reflect.TypeOf((*MockAwsApiGatewayClient)(nil).GetRestApis), varargs...)
+}
+
+// GetStages mocks base method.
+func (m *MockAwsApiGatewayClient) GetStages(arg0 context.Context, arg1 *apigateway.GetStagesInput, arg2 ...func(*apigateway.Options)) (*apigateway.GetStagesOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetStages", varargs...)
+	ret0, _ := ret[0].(*apigateway.GetStagesOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStages indicates an expected call of GetStages.
+func (mr *MockAwsApiGatewayClientMockRecorder) GetStages(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStages", reflect.TypeOf((*MockAwsApiGatewayClient)(nil).GetStages), varargs...)
+}
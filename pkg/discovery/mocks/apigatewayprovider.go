@@ -0,0 +1,77 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery (interfaces: AwsApiGatewayProvider)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	discovery "github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAwsApiGatewayProvider is a mock of AwsApiGatewayProvider interface.
+type MockAwsApiGatewayProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockAwsApiGatewayProviderMockRecorder
+}
+
+// MockAwsApiGatewayProviderMockRecorder is the mock recorder for MockAwsApiGatewayProvider.
+type MockAwsApiGatewayProviderMockRecorder struct {
+	mock *MockAwsApiGatewayProvider
+}
+
+// NewMockAwsApiGatewayProvider creates a new mock instance.
+func NewMockAwsApiGatewayProvider(ctrl *gomock.Controller) *MockAwsApiGatewayProvider {
+	mock := &MockAwsApiGatewayProvider{ctrl: ctrl}
+	mock.recorder = &MockAwsApiGatewayProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAwsApiGatewayProvider) EXPECT() *MockAwsApiGatewayProviderMockRecorder {
+	return m.recorder
+}
+
+// GetApiGatewayClient mocks base method.
+func (m *MockAwsApiGatewayProvider) GetApiGatewayClient() discovery.AwsApiGatewayClient {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApiGatewayClient")
+	ret0, _ := ret[0].(discovery.AwsApiGatewayClient)
+	return ret0
+}
+
+// GetApiGatewayClient indicates an expected call of GetApiGatewayClient.
+func (mr *MockAwsApiGatewayProviderMockRecorder) GetApiGatewayClient() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiGatewayClient", reflect.TypeOf((*MockAwsApiGatewayProvider)(nil).GetApiGatewayClient))
+}
+
+// GetApiGatewayV2Client mocks base method.
+func (m *MockAwsApiGatewayProvider) GetApiGatewayV2Client() discovery.AwsApiGatewayV2Client {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApiGatewayV2Client")
+	ret0, _ := ret[0].(discovery.AwsApiGatewayV2Client)
+	return ret0
+}
+
+// GetApiGatewayV2Client indicates an expected call of GetApiGatewayV2Client.
+func (mr *MockAwsApiGatewayProviderMockRecorder) GetApiGatewayV2Client() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiGatewayV2Client", reflect.TypeOf((*MockAwsApiGatewayProvider)(nil).GetApiGatewayV2Client))
+}
+
+// GetAwsGetRestApisPaginator mocks base method.
+func (m *MockAwsApiGatewayProvider) GetAwsGetRestApisPaginator() discovery.AwsGetRestApisPaginator {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAwsGetRestApisPaginator")
+	ret0, _ := ret[0].(discovery.AwsGetRestApisPaginator)
+	return ret0
+}
+
+// GetAwsGetRestApisPaginator indicates an expected call of GetAwsGetRestApisPaginator.
+func (mr *MockAwsApiGatewayProviderMockRecorder) GetAwsGetRestApisPaginator() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAwsGetRestApisPaginator", reflect.TypeOf((*MockAwsApiGatewayProvider)(nil).GetAwsGetRestApisPaginator))
+}
@@ -0,0 +1,76 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery (interfaces: AwsApiGatewayV2Client)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	apigatewayv2 "github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAwsApiGatewayV2Client is a mock of AwsApiGatewayV2Client interface.
+type MockAwsApiGatewayV2Client struct {
+	ctrl     *gomock.Controller
+	recorder *MockAwsApiGatewayV2ClientMockRecorder
+}
+
+// MockAwsApiGatewayV2ClientMockRecorder is the mock recorder for MockAwsApiGatewayV2Client.
+type MockAwsApiGatewayV2ClientMockRecorder struct {
+	mock *MockAwsApiGatewayV2Client
+}
+
+// NewMockAwsApiGatewayV2Client creates a new mock instance.
+func NewMockAwsApiGatewayV2Client(ctrl *gomock.Controller) *MockAwsApiGatewayV2Client {
+	mock := &MockAwsApiGatewayV2Client{ctrl: ctrl}
+	mock.recorder = &MockAwsApiGatewayV2ClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAwsApiGatewayV2Client) EXPECT() *MockAwsApiGatewayV2ClientMockRecorder {
+	return m.recorder
+}
+
+// GetApis mocks base method.
+func (m *MockAwsApiGatewayV2Client) GetApis(arg0 context.Context, arg1 *apigatewayv2.GetApisInput, arg2 ...func(*apigatewayv2.Options)) (*apigatewayv2.GetApisOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetApis", varargs...)
+	ret0, _ := ret[0].(*apigatewayv2.GetApisOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApis indicates an expected call of GetApis.
+func (mr *MockAwsApiGatewayV2ClientMockRecorder) GetApis(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApis", reflect.TypeOf((*MockAwsApiGatewayV2Client)(nil).GetApis), varargs...)
+}
+
+// GetStages mocks base method.
+func (m *MockAwsApiGatewayV2Client) GetStages(arg0 context.Context, arg1 *apigatewayv2.GetStagesInput, arg2 ...func(*apigatewayv2.Options)) (*apigatewayv2.GetStagesOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetStages", varargs...)
+	ret0, _ := ret[0].(*apigatewayv2.GetStagesOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStages indicates an expected call of GetStages.
+func (mr *MockAwsApiGatewayV2ClientMockRecorder) GetStages(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStages", reflect.TypeOf((*MockAwsApiGatewayV2Client)(nil).GetStages), varargs...)
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery (interfaces: AwsGetRestApisPaginator)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	apigateway "github.com/aws/aws-sdk-go-v2/service/apigateway"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAwsGetRestApisPaginator is a mock of AwsGetRestApisPaginator interface.
+type MockAwsGetRestApisPaginator struct {
+	ctrl     *gomock.Controller
+	recorder *MockAwsGetRestApisPaginatorMockRecorder
+}
+
+// MockAwsGetRestApisPaginatorMockRecorder is the mock recorder for MockAwsGetRestApisPaginator.
+type MockAwsGetRestApisPaginatorMockRecorder struct {
+	mock *MockAwsGetRestApisPaginator
+}
+
+// NewMockAwsGetRestApisPaginator creates a new mock instance.
+func NewMockAwsGetRestApisPaginator(ctrl *gomock.Controller) *MockAwsGetRestApisPaginator {
+	mock := &MockAwsGetRestApisPaginator{ctrl: ctrl}
+	mock.recorder = &MockAwsGetRestApisPaginatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAwsGetRestApisPaginator) EXPECT() *MockAwsGetRestApisPaginatorMockRecorder {
+	return m.recorder
+}
+
+// HasMorePages mocks base method.
+func (m *MockAwsGetRestApisPaginator) HasMorePages() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasMorePages")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasMorePages indicates an expected call of HasMorePages.
+func (mr *MockAwsGetRestApisPaginatorMockRecorder) HasMorePages() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasMorePages", reflect.TypeOf((*MockAwsGetRestApisPaginator)(nil).HasMorePages))
+}
+
+// NextPage mocks base method.
+func (m *MockAwsGetRestApisPaginator) NextPage(arg0 context.Context, arg1 ...func(*apigateway.Options)) (*apigateway.GetRestApisOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "NextPage", varargs...)
+	ret0, _ := ret[0].(*apigateway.GetRestApisOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NextPage indicates an expected call of NextPage.
+func (mr *MockAwsGetRestApisPaginatorMockRecorder) NextPage(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextPage", reflect.TypeOf((*MockAwsGetRestApisPaginator)(nil).NextPage), varargs...)
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery (interfaces: AwsStsClient)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	sts "github.com/aws/aws-sdk-go-v2/service/sts"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAwsStsClient is a mock of AwsStsClient interface.
+type MockAwsStsClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAwsStsClientMockRecorder
+}
+
+// MockAwsStsClientMockRecorder is the mock recorder for MockAwsStsClient.
+type MockAwsStsClientMockRecorder struct {
+	mock *MockAwsStsClient
+}
+
+// NewMockAwsStsClient creates a new mock instance.
+func NewMockAwsStsClient(ctrl *gomock.Controller) *MockAwsStsClient {
+	mock := &MockAwsStsClient{ctrl: ctrl}
+	mock.recorder = &MockAwsStsClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAwsStsClient) EXPECT() *MockAwsStsClientMockRecorder {
+	return m.recorder
+}
+
+// GetCallerIdentity mocks base method.
+func (m *MockAwsStsClient) GetCallerIdentity(arg0 context.Context, arg1 *sts.GetCallerIdentityInput, arg2 ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetCallerIdentity", varargs...)
+	ret0, _ := ret[0].(*sts.GetCallerIdentityOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCallerIdentity indicates an expected call of GetCallerIdentity.
+func (mr *MockAwsStsClientMockRecorder) GetCallerIdentity(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCallerIdentity", reflect.TypeOf((*MockAwsStsClient)(nil).GetCallerIdentity), varargs...)
+}
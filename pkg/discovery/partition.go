@@ -0,0 +1,71 @@
+package discovery
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// Partition is a group of AWS regions with its own ARNs, endpoints and console.
+type Partition string
+
+const (
+	PartitionAws      Partition = "aws"
+	PartitionAwsCn    Partition = "aws-cn"
+	PartitionAwsUsGov Partition = "aws-us-gov"
+)
+
+var Partitions = []string{string(PartitionAws), string(PartitionAwsCn), string(PartitionAwsUsGov)}
+
+// PartitionOfRegion returns the partition of a region, aws for the regions it doesn't know.
+func PartitionOfRegion(region string) Partition {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return PartitionAwsCn
+	case strings.HasPrefix(region, "us-gov-"):
+		return PartitionAwsUsGov
+	}
+	return PartitionAws
+}
+
+// consoleHost is the host of the AWS console of the partition, prefixed with the region.
+func (p Partition) consoleHost() string {
+	switch p {
+	case PartitionAwsCn:
+		return "console.amazonaws.cn"
+	case PartitionAwsUsGov:
+		return "console.amazonaws-us-gov.com"
+	}
+	return "console.aws.amazon.com"
+}
+
+// ParseArn parses an ARN of one of Partitions.
+func ParseArn(value string) (arn.ARN, error) {
+	parsed, err := arn.Parse(value)
+	if err != nil {
+		return parsed, fmt.Errorf("%q is not an ARN", value)
+	}
+	if !slices.Contains(Partitions, parsed.Partition) {
+		return parsed, fmt.Errorf("%q is not in one of the partitions %s", value, StringFromArray(Partitions))
+	}
+	return parsed, nil
+}
+
+// RoleArn returns the ARN of an IAM role, name may start with the path of the role.
+func RoleArn(partition Partition, accountId string, name string) string {
+	return arn.ARN{Partition: string(partition), Service: "iam", AccountID: accountId, Resource: "role/" + name}.String()
+}
+
+// LogGroupArn returns the ARN of a log group, as set in the access log settings of stages.
+func LogGroupArn(partition Partition, region string, accountId string, name string) string {
+	return arn.ARN{Partition: string(partition), Service: "logs", Region: region, AccountID: accountId, Resource: "log-group:" + name}.String()
+}
+
+func accountPartition(account AccountSpec, override Partition) Partition {
+	if override != "" {
+		return override
+	}
+	return PartitionOfRegion(account.Region)
+}
@@ -0,0 +1,57 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartition(t *testing.T) {
+	tests := []struct {
+		region      string
+		partition   Partition
+		roleArn     string
+		logGroupArn string
+		consoleUrl  string
+	}{
+		{
+			region:      "us-east-1",
+			partition:   PartitionAws,
+			roleArn:     "arn:aws:iam::123456789012:role/traceable",
+			logGroupArn: "arn:aws:logs:us-east-1:123456789012:log-group:access-logs",
+			consoleUrl:  "https://us-east-1.console.aws.amazon.com/apigateway/main/apis/api1/stages?api=api1&region=us-east-1",
+		},
+		{
+			region:      "us-gov-west-1",
+			partition:   PartitionAwsUsGov,
+			roleArn:     "arn:aws-us-gov:iam::123456789012:role/traceable",
+			logGroupArn: "arn:aws-us-gov:logs:us-gov-west-1:123456789012:log-group:access-logs",
+			consoleUrl:  "https://us-gov-west-1.console.amazonaws-us-gov.com/apigateway/main/apis/api1/stages?api=api1&region=us-gov-west-1",
+		},
+		{
+			region:      "cn-northwest-1",
+			partition:   PartitionAwsCn,
+			roleArn:     "arn:aws-cn:iam::123456789012:role/traceable",
+			logGroupArn: "arn:aws-cn:logs:cn-northwest-1:123456789012:log-group:access-logs",
+			consoleUrl:  "https://cn-northwest-1.console.amazonaws.cn/apigateway/main/apis/api1/stages?api=api1&region=cn-northwest-1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.region, func(t *testing.T) {
+			partition := PartitionOfRegion(test.region)
+			assert.Equal(t, test.partition, partition)
+			assert.Equal(t, test.roleArn, RoleArn(partition, "123456789012", "traceable"))
+			assert.NoError(t, ValidateRoleArn(test.roleArn))
+			assert.Equal(t, test.logGroupArn, LogGroupArn(partition, test.region, "123456789012", "access-logs"))
+			assert.NoError(t, ValidateLogGroupArn(test.logGroupArn))
+			assert.Equal(t, "access-logs", AccessLogGroupNameFromArn(test.logGroupArn))
+			assert.Equal(t, test.consoleUrl, getStageConsoleUrl(partition, test.region, "api1"))
+		})
+	}
+
+	_, err := ParseArn("arn:aws-eu:iam::123456789012:role/traceable")
+	assert.Error(t, err)
+	assert.Empty(t, AccessLogGroupNameFromArn("arn:aws:firehose:us-east-1:123456789012:deliverystream/access-logs"))
+	assert.Equal(t, PartitionAwsUsGov, accountPartition(AccountSpec{Region: "us-east-1"}, PartitionAwsUsGov))
+}
@@ -0,0 +1,470 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type ReportFormat string
+
+const (
+	ReportFormatJson  ReportFormat = "json"
+	ReportFormatSarif ReportFormat = "sarif"
+	ReportFormatJunit ReportFormat = "junit"
+	ReportFormatCsv   ReportFormat = "csv"
+)
+
+var ReportFormats = []string{string(ReportFormatJson), string(ReportFormatSarif), string(ReportFormatJunit), string(ReportFormatCsv)}
+
+const (
+	reportToolName = "terraform-provider-awsapigateway"
+	reportToolUri  = "https://github.com/Traceableai/terraform-provider-awsapigateway"
+	// accountErrorCode is the code of failed accounts in reports
+	accountErrorCode = "account_error"
+)
+
+const (
+	StagePassed  = "passed"
+	StageWarning = "warning"
+	StageFailed  = "failed"
+)
+
+// Result has no timestamps so that the reports of successive runs can be diffed.
+type Result struct {
+	LogGroupNames []string      `json:"log_group_names"`
+	Stages        []StageResult `json:"stages"`
+	// Findings are the findings not tied to a stage
+	Findings       []Finding       `json:"findings"`
+	FailedAccounts []FailedAccount `json:"failed_accounts"`
+	// Selections explain why each api and stage was checked or left out
+	Selections []Selection `json:"selections"`
+}
+
+type StageResult struct {
+	AccountIndex      int              `json:"account_index"`
+	AccountId         string           `json:"account_id"`
+	Region            string           `json:"region"`
+	ApiId             string           `json:"api_id"`
+	StageName         string           `json:"stage_name"`
+	ApiType           string           `json:"api_type"`
+	Status            string           `json:"status"`
+	ExecutionLogging  string           `json:"execution_logging"`
+	MethodOverrides   []MethodOverride `json:"method_overrides"`
+	AccessLogGroup    string           `json:"access_log_group"`
+	AccessLogFormat   *AccessLogFormat `json:"access_log_format"`
+	SelectedLogGroups []string         `json:"selected_log_groups"`
+	Findings          []Finding        `json:"findings"`
+}
+
+type MethodOverride struct {
+	Method           string `json:"method"`
+	LoggingLevel     string `json:"logging_level"`
+	DataTraceEnabled bool   `json:"data_trace_enabled"`
+}
+
+type AccessLogFormat struct {
+	Format        string            `json:"format"`
+	Json          bool              `json:"json"`
+	VariableKeys  map[string]string `json:"variable_keys"`
+	MissingValues []string          `json:"missing_values"`
+}
+
+// Finding is a finding with its severity, findings not about a stage have no api id or
+// stage name. ApiListIndex is the entry of SelectorList that selected the stage, or -1.
+type Finding struct {
+	AccountIndex int             `json:"account_index"`
+	AccountId    string          `json:"account_id"`
+	Region       string          `json:"region"`
+	ApiId        string          `json:"api_id,omitempty"`
+	StageName    string          `json:"stage_name,omitempty"`
+	Code         string          `json:"code"`
+	Severity     FindingSeverity `json:"severity"`
+	// Summary is the message without the value it is about
+	Summary      string `json:"summary"`
+	Value        string `json:"value,omitempty"`
+	Message      string `json:"message"`
+	Detail       string `json:"detail,omitempty"`
+	ApiListIndex int    `json:"api_list_index"`
+	// SelectorList is include_apis or api_list, or exclude_apis for wrong syntax findings
+	SelectorList string `json:"selector_list,omitempty"`
+	ConsoleUrl   string `json:"console_url,omitempty"`
+}
+
+func newResult(logGroupNames []string, stageInventory *inventory, failedAccounts []FailedAccount, collector *findingCollector) *Result {
+	result := &Result{
+		LogGroupNames:  logGroupNames,
+		Stages:         []StageResult{},
+		Findings:       []Finding{},
+		FailedAccounts: append([]FailedAccount{}, failedAccounts...),
+		Selections:     append([]Selection{}, stageInventory.selections...),
+	}
+	if result.LogGroupNames == nil {
+		result.LogGroupNames = []string{}
+	}
+	stageIndexes := make(map[string]int)
+	for _, stage := range stageInventory.stages {
+		stageResult := StageResult{
+			AccountIndex:      stage.account.Index,
+			AccountId:         stage.account.AccountId,
+			Region:            stage.account.Region,
+			ApiId:             stage.apiId,
+			StageName:         stage.stageName,
+			ApiType:           stage.apiType,
+			Status:            StagePassed,
+			ExecutionLogging:  string(stage.executionLogging),
+			MethodOverrides:   []MethodOverride{},
+			AccessLogGroup:    stage.accessLogGroup,
+			SelectedLogGroups: append([]string{}, stage.selectedLogGroups...),
+			Findings:          []Finding{},
+		}
+		for _, override := range stage.methodOverrides {
+			stageResult.MethodOverrides = append(stageResult.MethodOverrides, MethodOverride{
+				Method:           override.method,
+				LoggingLevel:     override.loggingLevel,
+				DataTraceEnabled: override.dataTraceEnabled,
+			})
+		}
+		if stage.accessLogFormat != nil {
+			stageResult.AccessLogFormat = &AccessLogFormat{
+				Format:        stage.accessLogFormat.format,
+				Json:          stage.accessLogFormat.json,
+				VariableKeys:  stage.accessLogFormat.variableKeys,
+				MissingValues: append([]string{}, stage.accessLogFormat.missingValues...),
+			}
+		}
+		stageIndexes[stageKey(stage.account.Index, stage.apiId, stage.stageName)] = len(result.Stages)
+		result.Stages = append(result.Stages, stageResult)
+	}
+
+	for _, finding := range collector.findings {
+		index, found := stageIndexes[stageKey(finding.AccountIndex, finding.ApiId, finding.StageName)]
+		if finding.ApiId == "" || !found {
+			result.Findings = append(result.Findings, finding)
+			continue
+		}
+		stage := &result.Stages[index]
+		stage.Findings = append(stage.Findings, finding)
+		if finding.Severity == FindingSeverityError {
+			stage.Status = StageFailed
+		} else if finding.Severity == FindingSeverityWarning && stage.Status == StagePassed {
+			stage.Status = StageWarning
+		}
+	}
+	return result
+}
+
+func stageKey(accountIndex int, apiId string, stageName string) string {
+	return fmt.Sprintf("%d/%s/%s", accountIndex, apiId, stageName)
+}
+
+// AllFindings returns the findings not tied to a stage followed by those of each stage.
+func (r *Result) AllFindings() []Finding {
+	findings := append([]Finding{}, r.Findings...)
+	for _, stage := range r.Stages {
+		findings = append(findings, stage.Findings...)
+	}
+	return findings
+}
+
+// HasErrors reports whether a finding has the error severity, failed accounts are not findings.
+func (r *Result) HasErrors() bool {
+	for _, finding := range r.AllFindings() {
+		if finding.Severity == FindingSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Result) Encode(format ReportFormat) ([]byte, error) {
+	switch format {
+	case ReportFormatSarif:
+		return r.sarif()
+	case ReportFormatJunit:
+		return r.junit()
+	case ReportFormatCsv:
+		return r.csv()
+	default:
+		return json.MarshalIndent(r, "", "  ")
+	}
+}
+
+func (r *Result) WriteReport(reportPath string, format ReportFormat) error {
+	content, err := r.Encode(format)
+	if err != nil {
+		return fmt.Errorf("building %s report: %w", format, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(reportPath), 0o755); err != nil {
+		return fmt.Errorf("creating report directory: %w", err)
+	}
+	if err := os.WriteFile(reportPath, content, 0o644); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                  sarif                                    //
+///////////////////////////////////////////////////////////////////////////////
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	Help             sarifMessage `json:"help"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+func (r *Result) sarif() ([]byte, error) {
+	rules := []sarifRule{{
+		Id:               accountErrorCode,
+		ShortDescription: sarifMessage{Text: "Account could not be checked"},
+		Help:             sarifMessage{Text: "Check the region and the cross account role of the account."},
+	}}
+	var summaries []string
+	for summary := range findingCodes {
+		summaries = append(summaries, string(summary))
+	}
+	sort.Strings(summaries)
+	for _, code := range FindingCodes() {
+		for _, summary := range summaries {
+			if Summary(summary).code() == code {
+				rules = append(rules, sarifRule{
+					Id:               code,
+					ShortDescription: sarifMessage{Text: summary},
+					Help:             sarifMessage{Text: remediations[Summary(summary)]},
+				})
+				break
+			}
+		}
+	}
+
+	results := []sarifResult{}
+	addResult := func(finding Finding) {
+		level := string(finding.Severity)
+		if finding.Severity == FindingSeverityIgnore {
+			level = "none"
+		}
+		name := strings.Trim(strings.Join([]string{finding.ApiId, finding.StageName}, "/"), "/")
+		results = append(results, sarifResult{
+			RuleId:  finding.Code,
+			Level:   level,
+			Message: sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+				Name:               name,
+				FullyQualifiedName: strings.Trim(strings.Join([]string{finding.AccountId, finding.Region, name}, "/"), "/"),
+				Kind:               "resource",
+			}}}},
+		})
+	}
+	for _, stage := range r.Stages {
+		for _, finding := range stage.Findings {
+			addResult(finding)
+		}
+	}
+	for _, finding := range r.Findings {
+		addResult(finding)
+	}
+	for _, failedAccount := range r.FailedAccounts {
+		addResult(Finding{
+			AccountId: failedAccount.AccountId,
+			Region:    failedAccount.Region,
+			Code:      accountErrorCode,
+			Severity:  FindingSeverityError,
+			Message:   failedAccount.Error,
+		})
+	}
+
+	return json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: reportToolName, InformationUri: reportToolUri, Rules: rules}},
+			Results: results,
+		}},
+	}, "", "  ")
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                  junit                                    //
+///////////////////////////////////////////////////////////////////////////////
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string         `xml:"classname,attr"`
+	Name      string         `xml:"name,attr"`
+	Failures  []junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure  `xml:"error,omitempty"`
+	SystemOut string         `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junit reports a test suite per account and a test case per stage.
+func (r *Result) junit() ([]byte, error) {
+	suites := junitTestSuites{Name: reportToolName}
+	suiteIndexes := make(map[int]int)
+	suite := func(accountIndex int, accountId string, region string) *junitTestSuite {
+		index, found := suiteIndexes[accountIndex]
+		if !found {
+			name := strings.TrimSpace(fmt.Sprintf("%s %s", accountId, region))
+			if name == "" {
+				name = "account " + strconv.Itoa(accountIndex)
+			}
+			index = len(suites.TestSuites)
+			suiteIndexes[accountIndex] = index
+			suites.TestSuites = append(suites.TestSuites, junitTestSuite{Name: name})
+		}
+		return &suites.TestSuites[index]
+	}
+
+	for _, stage := range r.Stages {
+		testCase := junitTestCase{ClassName: stage.ApiId, Name: fmt.Sprintf("%s (%s)", stage.StageName, stage.ApiType)}
+		var warnings []string
+		for _, finding := range stage.Findings {
+			switch finding.Severity {
+			case FindingSeverityError:
+				testCase.Failures = append(testCase.Failures, junitFailure{Type: finding.Code, Message: finding.Message, Text: finding.Detail})
+			case FindingSeverityWarning:
+				warnings = append(warnings, fmt.Sprintf("%s: %s", finding.Code, finding.Message))
+			}
+		}
+		testCase.SystemOut = strings.Join(warnings, "\n")
+		s := suite(stage.AccountIndex, stage.AccountId, stage.Region)
+		s.TestCases = append(s.TestCases, testCase)
+		s.Tests++
+		if len(testCase.Failures) > 0 {
+			s.Failures++
+		}
+	}
+	for _, failedAccount := range r.FailedAccounts {
+		s := suite(failedAccount.AccountIndex, failedAccount.AccountId, failedAccount.Region)
+		s.TestCases = append(s.TestCases, junitTestCase{
+			ClassName: "account",
+			Name:      "discovery",
+			Error:     &junitFailure{Type: accountErrorCode, Message: failedAccount.Error},
+		})
+		s.Tests++
+		s.Errors++
+	}
+	for _, s := range suites.TestSuites {
+		suites.Tests += s.Tests
+		suites.Failures += s.Failures
+		suites.Errors += s.Errors
+	}
+
+	content, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), content...), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                   csv                                     //
+///////////////////////////////////////////////////////////////////////////////
+
+var reportCsvHeader = []string{
+	"account_id", "region", "api_id", "stage_name", "api_type", "status", "finding_codes", "execution_logging",
+	"access_log_group", "access_log_format_json", "missing_access_log_values", "selected_log_groups",
+}
+
+func (r *Result) csv() ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	if err := writer.Write(reportCsvHeader); err != nil {
+		return nil, err
+	}
+	for _, stage := range r.Stages {
+		var codes []string
+		for _, finding := range stage.Findings {
+			if finding.Severity != FindingSeverityIgnore && !contains(codes, finding.Code) {
+				codes = append(codes, finding.Code)
+			}
+		}
+		sort.Strings(codes)
+		formatJson, missingValues := "", ""
+		if stage.AccessLogFormat != nil {
+			formatJson = strconv.FormatBool(stage.AccessLogFormat.Json)
+			missingValues = strings.Join(stage.AccessLogFormat.MissingValues, ";")
+		}
+		err := writer.Write([]string{
+			stage.AccountId, stage.Region, stage.ApiId, stage.StageName, stage.ApiType, stage.Status,
+			strings.Join(codes, ";"), stage.ExecutionLogging, stage.AccessLogGroup, formatJson, missingValues,
+			strings.Join(stage.SelectedLogGroups, ";"),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
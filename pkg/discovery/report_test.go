@@ -0,0 +1,141 @@
+package discovery
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testResult() *Result {
+	account := findingAccount{Index: 0, AccountId: "123456789012", Region: "us-east-1"}
+	collector := newFindingCollector()
+	collector.setFindingSeverity(map[string]FindingSeverity{"execution_log_not_enabled": FindingSeverityIgnore})
+	sel := newSelection(Selector{ApiList: []string{"api1", "api1/dev/extra"}})
+	collector.setAccount(account, sel)
+	stageInventory := &inventory{}
+	stageInventory.setAccount(account)
+	stageInventory.add(stageInfo{
+		apiId:             "api1",
+		stageName:         "dev",
+		apiType:           "REST",
+		executionLogging:  ExecutionLoggingFull,
+		accessLogGroup:    "access",
+		methodOverrides:   []methodLoggingSettings{{method: "/pets/GET", loggingLevel: "ERROR"}},
+		selectedLogGroups: []string{"API-Gateway-Execution-Logs_api1/dev", "access"},
+		accessLogFormat: &accessLogFormatAnalysis{
+			format:        `{"status":"$context.status"}`,
+			json:          true,
+			variableKeys:  map[string]string{"$context.status": "status"},
+			missingValues: []string{"$context.requestId"},
+		},
+	})
+	stageInventory.add(stageInfo{apiId: "api1", stageName: "prod", apiType: "REST"})
+	stageInventory.add(stageInfo{apiId: "api1", stageName: "test", apiType: "REST"})
+	collector.addError(AccessLogFormatMissingRequiredValues, "api1/dev", withMissingValues([]string{"$context.requestId"}))
+	collector.addStageWarn(ExecutionLogMethodOverride, "api1/dev", "api1/dev /pets/GET (ERROR)")
+	collector.addError(ExecutionLogNotEnabled, "api1/prod")
+	collector.addSelectorFinding(WrongSyntax, FindingSeverityWarning, sel.invalid[0])
+	failedAccounts := []FailedAccount{{AccountIndex: 1, Region: "eu-west-1", Error: "access denied"}}
+	return newResult([]string{"API-Gateway-Execution-Logs_api1/dev", "access"}, stageInventory, failedAccounts, collector)
+}
+
+func TestNewResult(t *testing.T) {
+	result := testResult()
+
+	assert.Len(t, result.Stages, 3)
+	dev, prod, test := result.Stages[0], result.Stages[1], result.Stages[2]
+	assert.Equal(t, StageFailed, dev.Status)
+	assert.Equal(t, "123456789012", dev.AccountId)
+	assert.Equal(t, []string{"$context.requestId"}, dev.AccessLogFormat.MissingValues)
+	assert.Equal(t, []MethodOverride{{Method: "/pets/GET", LoggingLevel: "ERROR"}}, dev.MethodOverrides)
+	var codes []string
+	for _, finding := range dev.Findings {
+		codes = append(codes, finding.Code+":"+string(finding.Severity))
+	}
+	assert.ElementsMatch(t, []string{"access_log_format_missing_required_values:error", "execution_log_method_override:warning"}, codes)
+
+	// ignored findings are kept in the report
+	assert.Equal(t, StagePassed, prod.Status)
+	assert.Len(t, prod.Findings, 1)
+	assert.Equal(t, FindingSeverityIgnore, prod.Findings[0].Severity)
+	assert.Equal(t, 0, prod.Findings[0].ApiListIndex)
+	assert.Equal(t, "Execution Logs not enabled for api1/prod", prod.Findings[0].Message)
+	assert.Equal(t, StagePassed, test.Status)
+	assert.Empty(t, test.Findings)
+
+	assert.Len(t, result.Findings, 1)
+	assert.Equal(t, "wrong_syntax", result.Findings[0].Code)
+	assert.Equal(t, 1, result.Findings[0].ApiListIndex)
+	assert.Equal(t, []FailedAccount{{AccountIndex: 1, Region: "eu-west-1", Error: "access denied"}}, result.FailedAccounts)
+}
+
+func TestWriteReport(t *testing.T) {
+	result := testResult()
+	dir := t.TempDir()
+
+	for _, format := range ReportFormats {
+		t.Run(format, func(t *testing.T) {
+			reportPath := filepath.Join(dir, "reports", "report."+format)
+			assert.NoError(t, result.WriteReport(reportPath, ReportFormat(format)))
+			content, err := os.ReadFile(reportPath)
+			assert.NoError(t, err)
+
+			switch ReportFormat(format) {
+			case ReportFormatJson:
+				var actual Result
+				assert.NoError(t, json.Unmarshal(content, &actual))
+				assert.Equal(t, *result, actual)
+			case ReportFormatSarif:
+				var actual sarifLog
+				assert.NoError(t, json.Unmarshal(content, &actual))
+				assert.Equal(t, "2.1.0", actual.Version)
+				// 3 stage findings, 1 finding without stage and 1 failed account
+				assert.Len(t, actual.Runs[0].Results, 5)
+				assert.Equal(t, "123456789012/us-east-1/api1/dev", actual.Runs[0].Results[0].Locations[0].LogicalLocations[0].FullyQualifiedName)
+				var rules []string
+				for _, rule := range actual.Runs[0].Tool.Driver.Rules {
+					rules = append(rules, rule.Id)
+				}
+				assert.ElementsMatch(t, append(FindingCodes(), accountErrorCode), rules)
+			case ReportFormatJunit:
+				var actual junitTestSuites
+				assert.NoError(t, xml.Unmarshal(content, &actual))
+				assert.Equal(t, 4, actual.Tests)
+				assert.Equal(t, 1, actual.Failures)
+				assert.Equal(t, 1, actual.Errors)
+				assert.Len(t, actual.TestSuites, 2)
+				assert.Contains(t, actual.TestSuites[0].TestCases[0].SystemOut, "execution_log_method_override")
+			case ReportFormatCsv:
+				records, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+				assert.NoError(t, err)
+				assert.Equal(t, reportCsvHeader, records[0])
+				assert.Equal(t, []string{
+					"123456789012", "us-east-1", "api1", "dev", "REST", "failed",
+					"access_log_format_missing_required_values;execution_log_method_override", "full", "access", "true",
+					"$context.requestId", "API-Gateway-Execution-Logs_api1/dev;access",
+				}, records[1])
+				assert.Len(t, records, 4)
+			}
+		})
+	}
+}
+
+func TestAllFindings(t *testing.T) {
+	result := testResult()
+
+	var codes []string
+	for _, finding := range result.AllFindings() {
+		codes = append(codes, finding.Code)
+	}
+	assert.Equal(t, []string{"wrong_syntax", "access_log_format_missing_required_values", "execution_log_method_override", "execution_log_not_enabled"}, codes)
+	assert.True(t, result.HasErrors())
+
+	result.Stages = result.Stages[1:]
+	assert.False(t, result.HasErrors())
+}
@@ -0,0 +1,249 @@
+package discovery
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Names of the lists holding selector entries.
+const (
+	selectorListApiList     = "api_list"
+	selectorListIncludeApis = "include_apis"
+	selectorListExcludeApis = "exclude_apis"
+)
+
+// Selection explains why an api or a stage was checked, StageName is empty for a whole api.
+type Selection struct {
+	AccountIndex int    `json:"account_index"`
+	AccountId    string `json:"account_id"`
+	Region       string `json:"region"`
+	ApiId        string `json:"api_id"`
+	ApiName      string `json:"api_name,omitempty"`
+	StageName    string `json:"stage_name,omitempty"`
+	Selected     bool   `json:"selected"`
+	Reason       string `json:"reason"`
+}
+
+func (s Selection) String() string {
+	name := s.ApiId
+	if s.StageName != "" {
+		name = s.ApiId + "/" + s.StageName
+	}
+	return fmt.Sprintf("%s: %s", name, s.Reason)
+}
+
+type selectorEntry struct {
+	list    string
+	index   int
+	value   string
+	exclude bool
+	api     string
+	stage   string
+	// patterns is false for the entries of api_list, which only match IDs exactly
+	patterns bool
+}
+
+func (e *selectorEntry) String() string {
+	return fmt.Sprintf("%s[%d] %q", e.list, e.index, e.value)
+}
+
+// specificity ranks the entries matching a stage, the most specific one decides.
+func (e *selectorEntry) specificity() int {
+	wildcards := e.patterns && (isPattern(e.api) || isPattern(e.stage))
+	switch {
+	case e.stage != "" && !wildcards:
+		return 4
+	case e.stage != "":
+		return 3
+	case !wildcards:
+		return 2
+	}
+	return 1
+}
+
+func (e *selectorEntry) matchesApi(apiId string, apiName string) bool {
+	return e.matches(e.api, apiId) || (e.patterns && apiName != "" && e.matches(e.api, apiName))
+}
+
+func (e *selectorEntry) matches(pattern string, value string) bool {
+	if !e.patterns {
+		return pattern == value
+	}
+	// the syntax of patterns is checked by newSelection
+	matched, _ := path.Match(pattern, value)
+	return matched
+}
+
+func isPattern(part string) bool {
+	return strings.ContainsAny(part, "*?[")
+}
+
+// selection decides which apis and stages of an account are checked, see Selector.
+type selection struct {
+	entries []*selectorEntry
+	// invalid are the entries with a wrong syntax, they select nothing
+	invalid     []*selectorEntry
+	includeList string
+	excludeList string
+	hasInclude  bool
+	apiNames    map[string]string
+	// matched are the entries that matched an api or a stage, the others are reported
+	matched map[*selectorEntry]bool
+}
+
+// decision holds the entry that decided, and overridden the entry of the other kind it won over.
+type decision struct {
+	selected   bool
+	entry      *selectorEntry
+	overridden *selectorEntry
+}
+
+func newSelection(selector Selector) *selection {
+	s := &selection{
+		includeList: selectorListIncludeApis,
+		excludeList: selectorListExcludeApis,
+		hasInclude:  len(selector.IncludeApis) > 0,
+		apiNames:    make(map[string]string),
+		matched:     make(map[*selectorEntry]bool),
+	}
+	if len(selector.ApiList) > 0 || selector.Exclude {
+		s.includeList, s.excludeList = selectorListApiList, selectorListApiList
+		s.hasInclude = !selector.Exclude
+		s.addEntries(selectorListApiList, selector.ApiList, selector.Exclude, false)
+	}
+	s.addEntries(selectorListIncludeApis, selector.IncludeApis, false, true)
+	s.addEntries(selectorListExcludeApis, selector.ExcludeApis, true, true)
+	return s
+}
+
+func (s *selection) addEntries(list string, values []string, exclude bool, patterns bool) {
+	for i, value := range values {
+		entry := &selectorEntry{list: list, index: i, value: value, exclude: exclude, patterns: patterns}
+		if ValidateSelectorEntry(value, patterns) != nil {
+			s.invalid = append(s.invalid, entry)
+			continue
+		}
+		entry.api, entry.stage, _ = strings.Cut(value, "/")
+		s.entries = append(s.entries, entry)
+	}
+}
+
+func validSelectorPart(part string, patterns bool) bool {
+	if part == "" {
+		return false
+	}
+	if patterns {
+		_, err := path.Match(part, "")
+		return err == nil
+	}
+	return true
+}
+
+func (s *selection) setApiName(apiId string, apiName string) {
+	s.apiNames[apiId] = apiName
+}
+
+// decide selects an api as a whole when stageName is empty, or a stage of it. An exclusion
+// wins over an inclusion that is as specific.
+func (s *selection) decide(apiId string, stageName string) decision {
+	apiName := s.apiNames[apiId]
+	var include, exclude *selectorEntry
+	for _, entry := range s.entries {
+		if !entry.matchesApi(apiId, apiName) {
+			continue
+		}
+		if entry.stage != "" && (stageName == "" || !entry.matches(entry.stage, stageName)) {
+			continue
+		}
+		s.matched[entry] = true
+		best := &include
+		if entry.exclude {
+			best = &exclude
+		}
+		if *best == nil || entry.specificity() > (*best).specificity() {
+			*best = entry
+		}
+	}
+	switch {
+	case include == nil && exclude == nil:
+		return decision{selected: !s.hasInclude}
+	case exclude == nil:
+		return decision{selected: true, entry: include}
+	case include == nil:
+		return decision{selected: false, entry: exclude}
+	case include.specificity() > exclude.specificity():
+		return decision{selected: true, entry: include, overridden: exclude}
+	}
+	return decision{selected: false, entry: exclude, overridden: include}
+}
+
+// needsStages tells whether the stages of an api must be listed.
+func (s *selection) needsStages(apiId string) bool {
+	if s.decide(apiId, "").selected {
+		return true
+	}
+	for _, entry := range s.entries {
+		if !entry.exclude && entry.stage != "" && entry.matchesApi(apiId, s.apiNames[apiId]) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipApi marks the entries of an api as matched although its stages are not listed.
+func (s *selection) skipApi(apiId string) {
+	for _, entry := range s.entries {
+		if entry.matchesApi(apiId, s.apiNames[apiId]) {
+			s.matched[entry] = true
+		}
+	}
+}
+
+func (s *selection) unmatched() []*selectorEntry {
+	var entries []*selectorEntry
+	for _, entry := range s.entries {
+		if !s.matched[entry] {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func (s *selection) explain(apiId string, stageName string, d decision) Selection {
+	return Selection{
+		ApiId:     apiId,
+		ApiName:   s.apiNames[apiId],
+		StageName: stageName,
+		Selected:  d.selected,
+		Reason:    s.reason(d),
+	}
+}
+
+func (s *selection) reason(d decision) string {
+	switch {
+	case d.entry == nil && d.selected:
+		return fmt.Sprintf("included, no entry of %s matches", s.excludeList)
+	case d.entry == nil:
+		return fmt.Sprintf("excluded, no entry of %s matches", s.includeList)
+	}
+	reason := "excluded by " + d.entry.String()
+	if d.selected {
+		reason = "included by " + d.entry.String()
+	}
+	if d.overridden == nil {
+		return reason
+	} else if d.overridden.specificity() == d.entry.specificity() {
+		return fmt.Sprintf("%s, exclusions win over inclusions as specific as %s", reason, d.overridden)
+	}
+	return fmt.Sprintf("%s, more specific than %s", reason, d.overridden)
+}
+
+// selectingEntry returns nil when the stage was selected by matching no exclusion.
+func (s *selection) selectingEntry(apiIdWithStageName string) *selectorEntry {
+	apiId, stageName, _ := strings.Cut(apiIdWithStageName, "/")
+	if d := s.decide(apiId, stageName); d.selected {
+		return d.entry
+	}
+	return nil
+}
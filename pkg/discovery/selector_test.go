@@ -0,0 +1,114 @@
+package discovery
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelection(t *testing.T) {
+	apiNames := map[string]string{"api1": "pets", "api2": "orders", "api3": "legacy-billing", "api4": "legacy-users"}
+	tests := []struct {
+		name     string
+		selector Selector
+		// expected maps api IDs and api/stage to the reason of their selection, selected
+		// ones start with "included"
+		expected map[string]string
+		invalid  []string
+	}{
+		{
+			name:     "all apis except one plus only a stage of another",
+			selector: Selector{IncludeApis: []string{"*", "api2/prod"}, ExcludeApis: []string{"api3", "api2"}},
+			expected: map[string]string{
+				"api1/dev":  `included by include_apis[0] "*"`,
+				"api2/prod": `included by include_apis[1] "api2/prod", more specific than exclude_apis[1] "api2"`,
+				"api2/dev":  `excluded by exclude_apis[1] "api2", more specific than include_apis[0] "*"`,
+				"api3":      `excluded by exclude_apis[0] "api3", more specific than include_apis[0] "*"`,
+			},
+		},
+		{
+			name:     "exclusions only",
+			selector: Selector{ExcludeApis: []string{"legacy-*", "api1/dev"}},
+			expected: map[string]string{
+				"api1/dev":  `excluded by exclude_apis[1] "api1/dev"`,
+				"api1/prod": "included, no entry of exclude_apis matches",
+				"api3":      `excluded by exclude_apis[0] "legacy-*"`,
+				"api4":      `excluded by exclude_apis[0] "legacy-*"`,
+			},
+		},
+		{
+			name:     "names and stage patterns",
+			selector: Selector{IncludeApis: []string{"pets", "orders/prod*"}, ExcludeApis: []string{"*/test"}},
+			expected: map[string]string{
+				"api1/dev":     `included by include_apis[0] "pets"`,
+				"api1/test":    `excluded by exclude_apis[0] "*/test", more specific than include_apis[0] "pets"`,
+				"api2/prod-eu": `included by include_apis[1] "orders/prod*"`,
+				"api2/dev":     "excluded, no entry of include_apis matches",
+				"api3":         "excluded, no entry of include_apis matches",
+			},
+		},
+		{
+			name:     "exclusions win over inclusions as specific",
+			selector: Selector{IncludeApis: []string{"api1/dev"}, ExcludeApis: []string{"api1/dev"}},
+			expected: map[string]string{
+				"api1/dev": `excluded by exclude_apis[0] "api1/dev", exclusions win over inclusions as specific as include_apis[0] "api1/dev"`,
+			},
+		},
+		{
+			name:     "api_list",
+			selector: Selector{ApiList: []string{"api1/dev", "api2", "pets", "api*", "a/b/c"}},
+			expected: map[string]string{
+				"api1/dev":  `included by api_list[0] "api1/dev"`,
+				"api1/prod": "excluded, no entry of api_list matches",
+				"api2/dev":  `included by api_list[1] "api2"`,
+				"api3":      "excluded, no entry of api_list matches",
+			},
+			invalid: []string{"a/b/c"},
+		},
+		{
+			name:     "api_list with exclude",
+			selector: Selector{ApiList: []string{"api1/dev", "api2"}, Exclude: true},
+			expected: map[string]string{
+				"api1/dev":  `excluded by api_list[0] "api1/dev"`,
+				"api1/prod": "included, no entry of api_list matches",
+				"api2":      `excluded by api_list[1] "api2"`,
+				"api3/dev":  "included, no entry of api_list matches",
+			},
+		},
+		{
+			name:     "wrong syntax",
+			selector: Selector{IncludeApis: []string{"api1/", "/dev", "api[", "api1/dev/x", "api1"}},
+			expected: map[string]string{
+				"api1/dev": `included by include_apis[4] "api1"`,
+			},
+			invalid: []string{"api1/", "/dev", "api[", "api1/dev/x"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sel := newSelection(test.selector)
+			for apiId, apiName := range apiNames {
+				sel.setApiName(apiId, apiName)
+			}
+			var invalid []string
+			for _, entry := range sel.invalid {
+				invalid = append(invalid, entry.value)
+			}
+			assert.Equal(t, test.invalid, invalid)
+
+			for value, reason := range test.expected {
+				apiId, stageName, stage := strings.Cut(value, "/")
+				if stage {
+					assert.True(t, sel.needsStages(apiId), value)
+				} else {
+					assert.False(t, sel.needsStages(apiId), value)
+				}
+				selection := sel.explain(apiId, stageName, sel.decide(apiId, stageName))
+				assert.Equal(t, reason, selection.Reason, value)
+				assert.Equal(t, strings.HasPrefix(reason, "included"), selection.Selected, value)
+			}
+		})
+	}
+}
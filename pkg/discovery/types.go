@@ -0,0 +1,730 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery/cassette"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	v1 "github.com/aws/aws-sdk-go-v2/service/apigateway"
+	v2 "github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// ApiGatewayAction is the last part of an account in an import ID.
+type ApiGatewayAction string
+
+const (
+	ApiGatewayActionInclude ApiGatewayAction = "include"
+	ApiGatewayActionExclude ApiGatewayAction = "exclude"
+)
+
+const (
+	RestApiType      = "REST"
+	HttpApiType      = "HTTP"
+	WebSocketApiType = "WEBSOCKET"
+
+	defaultMethodSettingsKey = "*/*"
+)
+
+var (
+	AccessLogFormatMandatoryValues = []string{"$context.httpMethod", "$context.domainName", "$context.status", "$context.path"}
+	// WebSocket messages have no http method or path, routes and connections identify them instead
+	WebSocketAccessLogFormatMandatoryValues = []string{"$context.domainName", "$context.status", "$context.routeKey", "$context.eventType", "$context.connectionId"}
+)
+
+type Summary string
+
+const (
+	WrongSyntax                          Summary = "api gateway syntax is wrong"
+	FullRequestAndResponseLogNotEnabled  Summary = "Full Request and Response Logs not enabled"
+	ExecutionLogErrorOnly                Summary = "Execution Logs set to Errors Only"
+	ExecutionLogNotEnabled               Summary = "Execution Logs not enabled"
+	AccessLogNotEnabledREST              Summary = "REST API Access Logs not enabled"
+	AccessLogNotEnabledHTTP              Summary = "HTTP API Access Logs not enabled"
+	AccessLogNotEnabledWebSocket         Summary = "WebSocket API Access Logs not enabled"
+	AccessLogFormatNotJson               Summary = "Access Log Format is not JSON parsable"
+	AccessLogFormatMissingRequiredValues Summary = "Access Log Format is missing required values"
+	ExecutionLogNotConfigured            Summary = "Execution Logs not configured"
+	ExecutionLogMethodOverride           Summary = "Execution Log settings overridden"
+	ExecutionLogLevelMissing             Summary = "Execution Log level missing"
+	AccessLogFormatMissing               Summary = "Access Log Format missing"
+	AccessLogDestinationNotLogGroup      Summary = "Access Log destination is not a CloudWatch Logs log group"
+	AccessLogFormatKeyMismatch           Summary = "Access Log Format has conflicting keys"
+	AccessLogFormatKeyMismatchExcluded   Summary = "Access Log Format has conflicting keys, log group excluded"
+	SelectorUnmatched                    Summary = "api selector matches no API or stage"
+)
+
+// FailOn decides whether accounts that could not be checked fail discovery.
+type FailOn string
+
+const (
+	FailOnAnyError          FailOn = "any_error"
+	FailOnAllAccountsFailed FailOn = "all_accounts_failed"
+	FailOnNever             FailOn = "never"
+)
+
+var FailOnModes = []string{string(FailOnAnyError), string(FailOnAllAccountsFailed), string(FailOnNever)}
+
+// Fails returns whether the failed accounts fail a discovery of the given number of accounts.
+func (f FailOn) Fails(failedAccounts int, accounts int) bool {
+	switch f {
+	case FailOnNever:
+		return false
+	case FailOnAllAccountsFailed:
+		return failedAccounts == accounts
+	}
+	return failedAccounts > 0
+}
+
+// FailedAccount is an account whose stages could not all be checked because of AWS errors.
+type FailedAccount struct {
+	AccountIndex        int    `json:"account_index"`
+	AccountId           string `json:"account_id"`
+	Region              string `json:"region"`
+	CrossAccountRoleArn string `json:"cross_account_role_arn"`
+	Error               string `json:"error"`
+}
+
+// FindingSeverity overrides the severity of a finding, keyed by its code in finding_severity.
+type FindingSeverity string
+
+const (
+	FindingSeverityError   FindingSeverity = "error"
+	FindingSeverityWarning FindingSeverity = "warning"
+	FindingSeverityIgnore  FindingSeverity = "ignore"
+)
+
+var FindingSeverities = []string{string(FindingSeverityError), string(FindingSeverityWarning), string(FindingSeverityIgnore)}
+
+// findingCodes are the stable names of the summaries used in finding_severity, both key
+// mismatch summaries share a code.
+var findingCodes = map[Summary]string{
+	WrongSyntax:                          "wrong_syntax",
+	FullRequestAndResponseLogNotEnabled:  "full_request_and_response_log_not_enabled",
+	ExecutionLogErrorOnly:                "execution_log_error_only",
+	ExecutionLogNotEnabled:               "execution_log_not_enabled",
+	AccessLogNotEnabledREST:              "access_log_not_enabled_rest",
+	AccessLogNotEnabledHTTP:              "access_log_not_enabled_http",
+	AccessLogNotEnabledWebSocket:         "access_log_not_enabled_websocket",
+	AccessLogFormatNotJson:               "access_log_format_not_json",
+	AccessLogFormatMissingRequiredValues: "access_log_format_missing_required_values",
+	ExecutionLogNotConfigured:            "execution_log_not_configured",
+	ExecutionLogMethodOverride:           "execution_log_method_override",
+	ExecutionLogLevelMissing:             "execution_log_level_missing",
+	AccessLogFormatMissing:               "access_log_format_missing",
+	AccessLogDestinationNotLogGroup:      "access_log_destination_not_log_group",
+	AccessLogFormatKeyMismatch:           "access_log_format_key_mismatch",
+	AccessLogFormatKeyMismatchExcluded:   "access_log_format_key_mismatch",
+	SelectorUnmatched:                    "selector_unmatched",
+}
+
+// FindingCodes returns the sorted codes accepted in finding_severity.
+func FindingCodes() []string {
+	var codes []string
+	for _, code := range findingCodes {
+		if !contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func (s Summary) code() string {
+	return findingCodes[s]
+}
+
+// accessLogFormatKeys holds the keys of each $context value written to a log group.
+type accessLogFormatKeys struct {
+	valueToKey map[string]AccessLogFormatKey
+	formats    map[string]string
+	conflicts  []AccessLogFormatConflict
+}
+
+// AccessLogFormatKey is the key a stage uses for a value in its access log format.
+type AccessLogFormatKey struct {
+	key                string
+	apiIdWithStageName string
+}
+
+// AccessLogFormatConflict records two stages writing a value to a log group under different keys.
+type AccessLogFormatConflict struct {
+	value  string
+	first  AccessLogFormatKey
+	second AccessLogFormatKey
+}
+
+func (m *accessLogFormatKeys) conflictingValues() []string {
+	var values []string
+	for _, conflict := range m.conflicts {
+		if !contains(values, conflict.value) {
+			values = append(values, conflict.value)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// conflictDetail lists the conflicting keys and the formats of the stages.
+func (m *accessLogFormatKeys) conflictDetail() string {
+	var lines []string
+	var stages []string
+	for _, conflict := range m.conflicts {
+		lines = append(lines, fmt.Sprintf("%s: key %q in %s, key %q in %s",
+			conflict.value,
+			conflict.first.key, conflict.first.apiIdWithStageName,
+			conflict.second.key, conflict.second.apiIdWithStageName))
+		for _, stage := range []string{conflict.first.apiIdWithStageName, conflict.second.apiIdWithStageName} {
+			if !contains(stages, stage) {
+				stages = append(stages, stage)
+			}
+		}
+	}
+	lines = append(lines, "")
+	for _, stage := range stages {
+		lines = append(lines, fmt.Sprintf("Format of %s: %s", stage, m.formats[stage]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+type ExecutionLogging string
+
+const (
+	ExecutionLoggingFull         ExecutionLogging = "full"
+	ExecutionLoggingInfo         ExecutionLogging = "info"
+	ExecutionLoggingError        ExecutionLogging = "error"
+	ExecutionLoggingOff          ExecutionLogging = "off"
+	ExecutionLoggingUnconfigured ExecutionLogging = "unconfigured"
+	ExecutionLoggingLevelMissing ExecutionLogging = "logging_level_missing"
+)
+
+// inventory collects the stages that were checked and the selection of every api and stage.
+type inventory struct {
+	stages     []stageInfo
+	selections []Selection
+	account    findingAccount
+}
+
+type stageInfo struct {
+	account          findingAccount
+	apiId            string
+	stageName        string
+	apiType          string
+	executionLogging ExecutionLogging
+	accessLogGroup   string
+	methodOverrides  []methodLoggingSettings
+	// accessLogFormat is nil when the stage has no access log format or it was not checked
+	accessLogFormat   *accessLogFormatAnalysis
+	selectedLogGroups []string
+}
+
+type accessLogFormatAnalysis struct {
+	format        string
+	json          bool
+	variableKeys  map[string]string
+	missingValues []string
+}
+
+func (s stageInfo) apiIdWithStageName() string {
+	return strings.Join([]string{s.apiId, s.stageName}, "/")
+}
+
+// methodLoggingSettings are the settings of a resource/method overriding those of */*.
+type methodLoggingSettings struct {
+	method           string
+	loggingLevel     string
+	dataTraceEnabled bool
+}
+
+func (m methodLoggingSettings) String() string {
+	if m.loggingLevel == "" {
+		return fmt.Sprintf("%s (logging level missing)", m.method)
+	} else if m.dataTraceEnabled {
+		return fmt.Sprintf("%s (%s with data trace)", m.method, m.loggingLevel)
+	}
+	return fmt.Sprintf("%s (%s)", m.method, m.loggingLevel)
+}
+
+func (i *inventory) add(stage stageInfo) {
+	stage.account = i.account
+	i.stages = append(i.stages, stage)
+}
+
+func (i *inventory) addSelection(selection Selection) {
+	selection.AccountIndex, selection.AccountId, selection.Region = i.account.Index, i.account.AccountId, i.account.Region
+	i.selections = append(i.selections, selection)
+}
+
+func (i *inventory) setAccount(account findingAccount) {
+	i.account = account
+}
+
+func (i *inventory) removeLogGroups(logGroupNames []string) {
+	for s := range i.stages {
+		if i.stages[s].account != i.account {
+			continue
+		}
+		var selectedLogGroups []string
+		for _, logGroupName := range i.stages[s].selectedLogGroups {
+			if !contains(logGroupNames, logGroupName) {
+				selectedLogGroups = append(selectedLogGroups, logGroupName)
+			}
+		}
+		i.stages[s].selectedLogGroups = selectedLogGroups
+	}
+}
+
+func (i *inventory) sort() {
+	sort.SliceStable(i.stages, func(a, b int) bool {
+		if i.stages[a].apiId != i.stages[b].apiId {
+			return i.stages[a].apiId < i.stages[b].apiId
+		}
+		return i.stages[a].stageName < i.stages[b].stageName
+	})
+	sort.SliceStable(i.selections, func(a, b int) bool {
+		if i.selections[a].AccountIndex != i.selections[b].AccountIndex {
+			return i.selections[a].AccountIndex < i.selections[b].AccountIndex
+		}
+		if i.selections[a].ApiId != i.selections[b].ApiId {
+			return i.selections[a].ApiId < i.selections[b].ApiId
+		}
+		return i.selections[a].StageName < i.selections[b].StageName
+	})
+}
+
+var remediations = map[Summary]string{
+	WrongSyntax:                          "Entries of include_apis, exclude_apis and api_list are either an API or api/stage, include_apis and exclude_apis may use the * and ? wildcards.",
+	FullRequestAndResponseLogNotEnabled:  "Set the CloudWatch logs of the stage to INFO and turn on data tracing (full request and response logs).",
+	ExecutionLogErrorOnly:                "Set the CloudWatch logs of the stage to INFO instead of ERROR and turn on data tracing.",
+	ExecutionLogNotEnabled:               "Turn on CloudWatch logs at the INFO level with data tracing for the stage.",
+	AccessLogNotEnabledREST:              "Turn on custom access logging for the stage with a CloudWatch Logs log group as destination and a JSON format.",
+	AccessLogNotEnabledHTTP:              "Turn on access logging for the stage with a CloudWatch Logs log group as destination and a JSON format.",
+	AccessLogNotEnabledWebSocket:         "Turn on access logging for the stage with a CloudWatch Logs log group as destination and a JSON format.",
+	AccessLogFormatNotJson:               "Use a JSON access log format, for example {\"requestId\":\"$context.requestId\",\"status\":\"$context.status\"}.",
+	AccessLogFormatMissingRequiredValues: "Add the missing variables to the access log format of the stage.",
+	ExecutionLogNotConfigured:            "The stage has no default method or route settings, turn on CloudWatch logs at the INFO level with data tracing.",
+	ExecutionLogMethodOverride:           "Remove the method or route level logging settings, or give them the logging level and data tracing of the stage.",
+	ExecutionLogLevelMissing:             "Set a logging level in the default method or route settings of the stage.",
+	AccessLogFormatMissing:               "Set an access log format for the stage.",
+	AccessLogDestinationNotLogGroup:      "Send the access logs of the stage to a CloudWatch Logs log group, other destinations such as Firehose are not read.",
+	SelectorUnmatched:                    "Check the ID, name or stage of the entry, the API may have been deleted or belong to another account or region.",
+}
+
+// findingCollector records the findings and the AWS errors of each account.
+type findingCollector struct {
+	findingSeverity map[string]FindingSeverity
+	findings        []Finding
+	accountErrors   []accountError
+	account         findingAccount
+	// selection selects the stages of the account, nil when findings are not tied to one
+	selection *selection
+}
+
+// findingAccount is the account being checked, Index is -1 for findings not tied to one.
+type findingAccount struct {
+	Index     int
+	AccountId string
+	Region    string
+	Partition Partition
+}
+
+type accountError struct {
+	account findingAccount
+	message string
+}
+
+func newFindingCollector() *findingCollector {
+	return &findingCollector{
+		account: findingAccount{Index: -1},
+	}
+}
+
+type summaryOption func(summary *string)
+
+func withMissingValues(values []string) summaryOption {
+	return func(summary *string) {
+		*summary = fmt.Sprintf("%s %s", *summary, StringFromArray(values))
+	}
+}
+func withValues(values []string) summaryOption {
+	return func(summary *string) {
+		*summary = fmt.Sprintf("%s for %s", *summary, StringFromArray(values))
+	}
+}
+func withLogGroupName(logGroupName string) summaryOption {
+	return func(summary *string) {
+		*summary = fmt.Sprintf("%s in log group %s", *summary, logGroupName)
+	}
+}
+func withDestination(destinationArn string) summaryOption {
+	return func(summary *string) {
+		*summary = fmt.Sprintf("%s (%s)", *summary, destinationArn)
+	}
+}
+func withAccount(account findingAccount) summaryOption {
+	return func(summary *string) {
+		if account.Region != "" {
+			*summary = fmt.Sprintf("%s in %s", *summary, account)
+		}
+	}
+}
+func (s Summary) new(opts ...summaryOption) string {
+	summary := string(s)
+	for _, opt := range opts {
+		opt(&summary)
+	}
+	return summary
+}
+
+func (a findingAccount) String() string {
+	return AccountName(a.AccountId, a.Region)
+}
+
+// AccountName names an account in messages, by its ID and region when the ID is known.
+func AccountName(accountId string, region string) string {
+	if accountId != "" {
+		return fmt.Sprintf("account %s (%s)", accountId, region)
+	}
+	return region
+}
+
+func (c *findingCollector) setAccount(account findingAccount, sel *selection) {
+	c.account = account
+	c.selection = sel
+}
+
+// selectingEntry returns the entry that selected a stage, an entry naming the stage wins.
+func (c *findingCollector) selectingEntry(apiIdWithStageName string) *selectorEntry {
+	if c.selection == nil {
+		return nil
+	}
+	return c.selection.selectingEntry(apiIdWithStageName)
+}
+
+func (c *findingCollector) setFindingSeverity(findingSeverity map[string]FindingSeverity) {
+	c.findingSeverity = findingSeverity
+}
+
+// severity returns the severity chosen for a summary, ignored findings are still recorded.
+func (c *findingCollector) severity(summary Summary, defaultSeverity FindingSeverity) FindingSeverity {
+	if severity, ok := c.findingSeverity[summary.code()]; ok {
+		return severity
+	}
+	return defaultSeverity
+}
+
+// addWithDetail adds a finding that is not about a single stage.
+func (c *findingCollector) addWithDetail(summary Summary, defaultSeverity FindingSeverity, text string, detail string) {
+	c.findings = append(c.findings, Finding{
+		AccountIndex: c.account.Index,
+		AccountId:    c.account.AccountId,
+		Region:       c.account.Region,
+		Code:         summary.code(),
+		Severity:     c.severity(summary, defaultSeverity),
+		Summary:      string(summary),
+		Message:      text,
+		Detail:       detail,
+		ApiListIndex: -1,
+	})
+}
+
+// addAccountError records an AWS error that keeps the account from being checked.
+func (c *findingCollector) addAccountError(message string) {
+	c.accountErrors = append(c.accountErrors, accountError{account: c.account, message: message})
+}
+
+func (c *findingCollector) failedAccounts(accounts []AccountSpec) []FailedAccount {
+	var failedAccounts []FailedAccount
+	for i, account := range accounts {
+		var messages []string
+		failedAccount := FailedAccount{AccountIndex: i, Region: account.Region, CrossAccountRoleArn: account.CrossAccountRoleArn}
+		for _, accountError := range c.accountErrors {
+			if accountError.account.Index == i {
+				failedAccount.AccountId = accountError.account.AccountId
+				messages = append(messages, accountError.message)
+			}
+		}
+		if len(messages) > 0 {
+			failedAccount.Error = strings.Join(messages, "; ")
+			failedAccounts = append(failedAccounts, failedAccount)
+		}
+	}
+	return failedAccounts
+}
+
+func (c *findingCollector) addError(summary Summary, apiIdWithStageName string, opts ...summaryOption) {
+	c.addFinding(FindingSeverityError, summary, apiIdWithStageName, apiIdWithStageName, opts...)
+}
+func (c *findingCollector) addWarn(summary Summary, apiIdWithStageName string, opts ...summaryOption) {
+	c.addFinding(FindingSeverityWarning, summary, apiIdWithStageName, apiIdWithStageName, opts...)
+}
+
+// addStageWarn adds a warning whose value describes more than the stage, such as a method.
+func (c *findingCollector) addStageWarn(summary Summary, apiIdWithStageName string, value string) {
+	c.addFinding(FindingSeverityWarning, summary, apiIdWithStageName, value)
+}
+
+func (c *findingCollector) addFinding(severity FindingSeverity, summary Summary, apiIdWithStageName string, value string, opts ...summaryOption) {
+	finding := Finding{
+		AccountIndex: c.account.Index,
+		AccountId:    c.account.AccountId,
+		Region:       c.account.Region,
+		Code:         summary.code(),
+		Severity:     c.severity(summary, severity),
+		Summary:      summary.new(opts...),
+		Value:        value,
+		Message:      summary.new(opts...) + " for " + value,
+		Detail:       remediations[summary],
+		ApiListIndex: -1,
+	}
+	finding.ApiId, finding.StageName, _ = strings.Cut(apiIdWithStageName, "/")
+	if c.account.Region != "" {
+		finding.ConsoleUrl = getStageConsoleUrl(c.account.Partition, c.account.Region, finding.ApiId)
+	}
+	if entry := c.selectingEntry(apiIdWithStageName); entry != nil {
+		finding.SelectorList, finding.ApiListIndex = entry.list, entry.index
+	}
+	c.findings = append(c.findings, finding)
+}
+
+// addSelectorFinding adds a finding about a selector entry, such as a wrong syntax.
+func (c *findingCollector) addSelectorFinding(summary Summary, severity FindingSeverity, entry *selectorEntry) {
+	c.findings = append(c.findings, Finding{
+		AccountIndex: c.account.Index,
+		AccountId:    c.account.AccountId,
+		Region:       c.account.Region,
+		Code:         summary.code(),
+		Severity:     c.severity(summary, severity),
+		Summary:      string(summary),
+		Value:        entry.value,
+		Message:      string(summary) + " for " + entry.value,
+		Detail:       remediations[summary],
+		SelectorList: entry.list,
+		ApiListIndex: entry.index,
+	})
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                           apiGatewayProvider                              //
+///////////////////////////////////////////////////////////////////////////////
+
+// The mocks of the client interfaces are regenerated with `go generate ./...`.
+//go:generate go run github.com/golang/mock/mockgen -destination=mocks/apigatewayprovider.go -package=mocks github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery AwsApiGatewayProvider
+//go:generate go run github.com/golang/mock/mockgen -destination=mocks/apigatewayclient.go -package=mocks github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery AwsApiGatewayClient
+//go:generate go run github.com/golang/mock/mockgen -destination=mocks/apigatewayv2client.go -package=mocks github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery AwsApiGatewayV2Client
+//go:generate go run github.com/golang/mock/mockgen -destination=mocks/stsclient.go -package=mocks github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery AwsStsClient
+//go:generate go run github.com/golang/mock/mockgen -destination=mocks/getrestapispaginator.go -package=mocks github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery AwsGetRestApisPaginator
+
+type AwsApiGatewayProvider interface {
+	GetAwsGetRestApisPaginator() AwsGetRestApisPaginator
+	GetApiGatewayClient() AwsApiGatewayClient
+	GetApiGatewayV2Client() AwsApiGatewayV2Client
+}
+
+type apiGatewayProvider struct {
+	apiGatewayClient   AwsApiGatewayClient
+	apiGatewayV2Client AwsApiGatewayV2Client
+}
+
+type AwsApiGatewayClient interface {
+	GetRestApis(ctx context.Context, params *v1.GetRestApisInput, optFns ...func(*v1.Options)) (*v1.GetRestApisOutput, error)
+	GetStages(ctx context.Context, params *v1.GetStagesInput, optFns ...func(*v1.Options)) (*v1.GetStagesOutput, error)
+}
+
+type AwsApiGatewayV2Client interface {
+	GetApis(ctx context.Context, params *v2.GetApisInput, optFns ...func(*v2.Options)) (*v2.GetApisOutput, error)
+	GetStages(ctx context.Context, params *v2.GetStagesInput, optFns ...func(*v2.Options)) (*v2.GetStagesOutput, error)
+}
+
+type AwsStsClient interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+type AwsGetRestApisPaginator interface {
+	HasMorePages() bool
+	NextPage(ctx context.Context, optFns ...func(*v1.Options)) (*v1.GetRestApisOutput, error)
+}
+
+var _ AwsApiGatewayProvider = (*apiGatewayProvider)(nil)
+
+func (p *apiGatewayProvider) GetAwsGetRestApisPaginator() AwsGetRestApisPaginator {
+	return v1.NewGetRestApisPaginator(p.apiGatewayClient, &v1.GetRestApisInput{})
+}
+
+func (p *apiGatewayProvider) GetApiGatewayClient() AwsApiGatewayClient {
+	return p.apiGatewayClient
+}
+
+func (p *apiGatewayProvider) GetApiGatewayV2Client() AwsApiGatewayV2Client {
+	return p.apiGatewayV2Client
+}
+
+// Clients are the AWS clients reading an account, Sts may be nil.
+type Clients struct {
+	ApiGateway   AwsApiGatewayClient
+	ApiGatewayV2 AwsApiGatewayV2Client
+	Sts          AwsStsClient
+}
+
+// ClientFactory returns the clients of an account, an error marks the account as failed.
+type ClientFactory func(ctx context.Context, account AccountSpec) (*Clients, error)
+
+// Endpoints override the endpoints of the AWS services, empty ones are resolved by the SDK.
+type Endpoints struct {
+	ApiGateway   string `json:"apigateway,omitempty" yaml:"apigateway,omitempty"`
+	ApiGatewayV2 string `json:"apigatewayv2,omitempty" yaml:"apigatewayv2,omitempty"`
+	Sts          string `json:"sts,omitempty" yaml:"sts,omitempty"`
+}
+
+// NewClients returns the clients of an AWS configuration.
+func NewClients(cfg aws.Config, endpoints Endpoints) *Clients {
+	return &Clients{
+		ApiGateway: v1.NewFromConfig(cfg, func(o *v1.Options) {
+			o.BaseEndpoint = baseEndpoint(endpoints.ApiGateway)
+		}),
+		ApiGatewayV2: v2.NewFromConfig(cfg, func(o *v2.Options) {
+			o.BaseEndpoint = baseEndpoint(endpoints.ApiGatewayV2)
+		}),
+		Sts: newStsClient(cfg, endpoints, ""),
+	}
+}
+
+func newStsClient(cfg aws.Config, endpoints Endpoints, region string) *sts.Client {
+	return sts.NewFromConfig(cfg, func(o *sts.Options) {
+		o.BaseEndpoint = baseEndpoint(endpoints.Sts)
+		if region != "" {
+			o.Region = region
+		}
+	})
+}
+
+func baseEndpoint(endpoint string) *string {
+	if endpoint == "" {
+		return nil
+	}
+	return aws.String(endpoint)
+}
+
+// DefaultClientFactory loads the default AWS configuration and assumes the cross account role.
+var DefaultClientFactory = NewClientFactory(Endpoints{})
+
+const (
+	// RecordCassetteEnv names a cassette file the sanitized responses of AWS are recorded to
+	RecordCassetteEnv = "AWSAPIGATEWAY_RECORD_CASSETTE"
+	// ReplayCassetteEnv names a cassette file the responses of AWS are replayed from
+	ReplayCassetteEnv = "AWSAPIGATEWAY_REPLAY_CASSETTE"
+)
+
+// NewClientFactory returns a factory like DefaultClientFactory using endpoints, optFns are
+// passed on to config.LoadDefaultConfig. Accounts sharing a role and an STS region share its
+// credentials.
+func NewClientFactory(endpoints Endpoints, optFns ...func(*config.LoadOptions) error) ClientFactory {
+	roles := &roleSessions{sessions: make(map[roleSessionKey]*roleSession)}
+	return func(ctx context.Context, account AccountSpec) (*Clients, error) {
+		cfg, err := config.LoadDefaultConfig(ctx, append([]func(*config.LoadOptions) error{config.WithRegion(account.Region)}, optFns...)...)
+		if err != nil {
+			return nil, err
+		}
+		if err := useCassette(&cfg); err != nil {
+			return nil, err
+		}
+		stsRegion := account.StsRegion
+		if stsRegion == "" {
+			stsRegion = account.Region
+		}
+		if account.CrossAccountRoleArn == "" {
+			clients := NewClients(cfg, endpoints)
+			clients.Sts = newStsClient(cfg, endpoints, stsRegion)
+			return clients, nil
+		}
+		session := roles.session(cfg, endpoints, account.CrossAccountRoleArn, stsRegion)
+		cfg.Credentials = session.credentials
+		clients := NewClients(cfg, endpoints)
+		clients.Sts = session.sts
+		return clients, nil
+	}
+}
+
+// AssumeRole returns the credentials of a role assumed through STS in stsRegion.
+func AssumeRole(cfg aws.Config, endpoints Endpoints, roleArn string, stsRegion string) *aws.CredentialsCache {
+	return aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(newStsClient(cfg, endpoints, stsRegion), roleArn))
+}
+
+type roleSessions struct {
+	mu       sync.Mutex
+	sessions map[roleSessionKey]*roleSession
+}
+
+type roleSessionKey struct {
+	roleArn   string
+	stsRegion string
+}
+
+type roleSession struct {
+	credentials *aws.CredentialsCache
+	sts         *sts.Client
+}
+
+func (r *roleSessions) session(cfg aws.Config, endpoints Endpoints, roleArn string, stsRegion string) *roleSession {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := roleSessionKey{roleArn: roleArn, stsRegion: stsRegion}
+	if session, found := r.sessions[key]; found {
+		return session
+	}
+	credentials := AssumeRole(cfg, endpoints, roleArn, stsRegion)
+	cfg.Credentials = credentials
+	session := &roleSession{credentials: credentials, sts: newStsClient(cfg, endpoints, stsRegion)}
+	r.sessions[key] = session
+	return session
+}
+
+var (
+	cassetteMu sync.Mutex
+	// cassettes are shared by all the accounts so that a run makes a single cassette
+	recorders = make(map[string]*cassette.Recorder)
+	replayers = make(map[string]*cassette.Replayer)
+)
+
+// useCassette records or replays the responses of AWS with the cassette of the environment.
+func useCassette(cfg *aws.Config) error {
+	recordPath, replayPath := os.Getenv(RecordCassetteEnv), os.Getenv(ReplayCassetteEnv)
+	if recordPath != "" && replayPath != "" {
+		return fmt.Errorf("%s and %s can't be set together", RecordCassetteEnv, ReplayCassetteEnv)
+	}
+
+	cassetteMu.Lock()
+	defer cassetteMu.Unlock()
+	if recordPath != "" {
+		recorder, found := recorders[recordPath]
+		if !found {
+			recorder = cassette.NewRecorder(recordPath)
+			recorders[recordPath] = recorder
+		}
+		cfg.HTTPClient = recorder.Client(cfg.HTTPClient)
+	} else if replayPath != "" {
+		replayer, found := replayers[replayPath]
+		if !found {
+			var err error
+			if replayer, err = cassette.NewReplayer(replayPath); err != nil {
+				return fmt.Errorf("loading the cassette of %s: %w", ReplayCassetteEnv, err)
+			}
+			replayers[replayPath] = replayer
+		}
+		cfg.HTTPClient = replayer
+		cfg.Credentials = credentials.NewStaticCredentialsProvider("REPLAY", "REPLAY", "")
+	}
+	return nil
+}
+
+func (c *Clients) provider() AwsApiGatewayProvider {
+	return &apiGatewayProvider{apiGatewayClient: c.ApiGateway, apiGatewayV2Client: c.ApiGatewayV2}
+}
@@ -0,0 +1,102 @@
+package discovery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func contains(arr []string, val string) bool {
+	for _, a := range arr {
+		if a == val {
+			return true
+		}
+	}
+	return false
+}
+
+func Flatten(m map[string]interface{}) map[string]interface{} {
+	o := make(map[string]interface{})
+	for k, v := range m {
+		switch child := v.(type) {
+		case map[string]interface{}:
+			nm := Flatten(child)
+			for nk, nv := range nm {
+				o[k+"."+nk] = nv
+			}
+		case []interface{}:
+			nm := make(map[string]interface{})
+			for i, elem := range child {
+				nm[strconv.Itoa(i)] = elem
+			}
+			for nk, nv := range Flatten(nm) {
+				o[k+"."+nk] = nv
+			}
+		default:
+			o[k] = v
+		}
+	}
+	return o
+}
+
+func removeDuplicates(arr []string) []string {
+	var newArr []string
+	chkMap := make(map[string]bool)
+	for _, a := range arr {
+		chkMap[a] = true
+	}
+	for a, _ := range chkMap {
+		newArr = append(newArr, a)
+	}
+	return newArr
+}
+
+func StringFromArray(values []string) string {
+	return fmt.Sprintf("[%s]", strings.Join(values, ", "))
+}
+
+func ExecutionLogGroupName(apiId string, stageName string) string {
+	return fmt.Sprintf("API-Gateway-Execution-Logs_%s/%s", apiId, stageName)
+}
+
+// ValidateLogGroupArn checks that arn is a CloudWatch Logs log group ARN such as
+// arn:PARTITION:logs:REGION:ACCOUNT_ID:log-group:LOG_GROUP_NAME.
+func ValidateLogGroupArn(arn string) error {
+	parsed, err := ParseArn(arn)
+	if err != nil || parsed.Service != "logs" || !strings.HasPrefix(parsed.Resource, "log-group:") || parsed.Resource == "log-group:" {
+		return fmt.Errorf("%q is not a CloudWatch Logs log group ARN", arn)
+	}
+	return nil
+}
+
+func getWebSocketExecutionLogGroupName(apiId string, stageName string) string {
+	return fmt.Sprintf("/aws/apigateway/%s/%s", apiId, stageName)
+}
+
+// getMethodPath converts a method settings key such as ~1orders~1{id}/GET into
+// /orders/{id}/GET.
+func getMethodPath(methodSettingsKey string) string {
+	if methodSettingsKey == defaultMethodSettingsKey {
+		return methodSettingsKey
+	}
+	methodPath := strings.ReplaceAll(methodSettingsKey, "~1", "/")
+	if !strings.HasPrefix(methodPath, "/") {
+		methodPath = "/" + methodPath
+	}
+	return methodPath
+}
+
+// getStageConsoleUrl links to the stages of an api in the API Gateway console of its partition.
+func getStageConsoleUrl(partition Partition, region string, apiId string) string {
+	return fmt.Sprintf("https://%[1]s.%[3]s/apigateway/main/apis/%[2]s/stages?api=%[2]s&region=%[1]s", region, apiId, partition.consoleHost())
+}
+
+// AccessLogGroupNameFromArn returns the log group name of an ARN, empty for other ARNs.
+func AccessLogGroupNameFromArn(arn string) string {
+	if ValidateLogGroupArn(arn) != nil {
+		return ""
+	}
+	parsed, _ := ParseArn(arn)
+	name, _ := strings.CutPrefix(parsed.Resource, "log-group:")
+	return name
+}
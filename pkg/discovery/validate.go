@@ -0,0 +1,86 @@
+package discovery
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MinTimeout and MaxTimeout bound the timeout of a discovery, shorter ones can't list the
+// apis of an account and longer ones would keep a plan waiting on a stuck account.
+const (
+	MinTimeout = time.Second
+	MaxTimeout = time.Hour
+)
+
+// DefaultCacheTtl is how long the provider keeps the responses of AWS by default, and
+// MaxCacheTtl bounds it since assumed role credentials last an hour.
+const (
+	DefaultCacheTtl = 5 * time.Minute
+	MaxCacheTtl     = time.Hour
+)
+
+var (
+	// regionPattern matches names such as us-gov-west-1, so that new regions are accepted
+	regionPattern    = regexp.MustCompile(`^[a-z]{2,4}(-[a-z]+)+-[0-9]{1,2}$`)
+	accountIdPattern = regexp.MustCompile(`^[0-9]{12}$`)
+	// roleResourcePattern matches role/PATH/NAME
+	roleResourcePattern = regexp.MustCompile(`^role/([\x21-\x7e]+/)?[\w+=,.@-]{1,64}$`)
+)
+
+// ValidateTimeout checks that timeout is a duration between MinTimeout and MaxTimeout.
+func ValidateTimeout(timeout string) error {
+	duration, err := time.ParseDuration(timeout)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration such as 30s or 5m", timeout)
+	}
+	if duration < MinTimeout || duration > MaxTimeout {
+		return fmt.Errorf("%q is not between %s and %s", timeout, MinTimeout, MaxTimeout)
+	}
+	return nil
+}
+
+// ValidateCacheTtl checks that ttl is a duration between 0 and MaxCacheTtl.
+func ValidateCacheTtl(ttl string) error {
+	duration, err := time.ParseDuration(ttl)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration such as 0s or 5m", ttl)
+	}
+	if duration < 0 || duration > MaxCacheTtl {
+		return fmt.Errorf("%q is not between 0s and %s", ttl, MaxCacheTtl)
+	}
+	return nil
+}
+
+// ValidateRegion checks that region is named like an AWS region.
+func ValidateRegion(region string) error {
+	if !regionPattern.MatchString(region) {
+		return fmt.Errorf("%q is not an AWS region such as us-east-1", region)
+	}
+	return nil
+}
+
+// ValidateRoleArn checks that arn is the ARN of an IAM role in one of Partitions.
+func ValidateRoleArn(arn string) error {
+	parsed, err := ParseArn(arn)
+	if err != nil || parsed.Service != "iam" || parsed.Region != "" || !accountIdPattern.MatchString(parsed.AccountID) ||
+		!roleResourcePattern.MatchString(parsed.Resource) {
+		return fmt.Errorf("%q is not an IAM role ARN such as arn:aws:iam::123456789012:role/traceable", arn)
+	}
+	return nil
+}
+
+// ValidateSelectorEntry checks the syntax of an api or api/stage selector entry.
+func ValidateSelectorEntry(value string, patterns bool) error {
+	parts := strings.Split(value, "/")
+	if len(parts) > 2 {
+		return fmt.Errorf("%q has more than one /, entries are an api or api/stage", value)
+	}
+	for _, part := range parts {
+		if !validSelectorPart(part, patterns) {
+			return fmt.Errorf("%q is not an api or api/stage", value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate func(string) error
+		valid    []string
+		invalid  []string
+	}{
+		{
+			name:     "timeout",
+			validate: ValidateTimeout,
+			valid:    []string{"1s", "30s", "1m", "1h"},
+			invalid:  []string{"", "10", "500ms", "61m", "-1m"},
+		},
+		{
+			name:     "cache ttl",
+			validate: ValidateCacheTtl,
+			valid:    []string{"0s", "500ms", "5m", "1h"},
+			invalid:  []string{"", "5", "-1s", "2h"},
+		},
+		{
+			name:     "region",
+			validate: ValidateRegion,
+			valid:    []string{"us-east-1", "eu-west-3", "us-gov-west-1", "cn-northwest-1", "us-isob-east-1", "ap-southeast-9"},
+			invalid:  []string{"", "us-east", "US-EAST-1", "moon-1", "us-east1", "us-east-1a"},
+		},
+		{
+			name:     "role arn",
+			validate: ValidateRoleArn,
+			valid: []string{
+				"arn:aws:iam::123456789012:role/traceable",
+				"arn:aws:iam::123456789012:role/service-role/traceable@audit",
+				"arn:aws-us-gov:iam::123456789012:role/traceable",
+				"arn:aws-cn:iam::123456789012:role/traceable",
+			},
+			invalid: []string{
+				"",
+				"test-arn-1",
+				"arn:aws:iam::12345678901:role/traceable",
+				"arn:aws:iam::123456789012:user/traceable",
+				"arn:aws-eu:iam::123456789012:role/traceable",
+				"arn:aws:sts::123456789012:assumed-role/traceable/session",
+			},
+		},
+		{
+			name:     "selector entry with patterns",
+			validate: func(value string) error { return ValidateSelectorEntry(value, true) },
+			valid:    []string{"api1", "api1/dev", "orders-*", "*/prod?"},
+			invalid:  []string{"", "api1/", "/dev", "api[", "api1/dev/extra"},
+		},
+		{
+			name:     "api_list entry",
+			validate: func(value string) error { return ValidateSelectorEntry(value, false) },
+			valid:    []string{"api1", "api1/dev", "api["},
+			invalid:  []string{"", "api1/", "api1/dev/extra"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, value := range test.valid {
+				assert.NoError(t, test.validate(value), value)
+			}
+			for _, value := range test.invalid {
+				assert.Error(t, test.validate(value), value)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		err    string
+	}{
+		{name: "defaults", config: Config{}},
+		{
+			name: "valid",
+			config: Config{Timeout: "30s", FailOn: "never", ReportFormat: "sarif",
+				FindingSeverity: map[string]string{"wrong_syntax": "warning"}},
+		},
+		{name: "timeout", config: Config{Timeout: "2h"}, err: `timeout "2h" is not between 1s and 1h0m0s`},
+		{
+			name:   "finding severity",
+			config: Config{FindingSeverity: map[string]string{"unknown": "error"}},
+			err:    `finding_severity "unknown" = "error" is not a finding code and one of [error, warning, ignore]`,
+		},
+		{name: "fail on", config: Config{FailOn: "sometimes"}, err: `fail_on "sometimes" is not one of [any_error, all_accounts_failed, never]`},
+		{name: "report format", config: Config{ReportFormat: "xml"}, err: `report_format "xml" is not one of [json, sarif, junit, csv]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.config.Validate()
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
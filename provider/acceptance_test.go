@@ -0,0 +1,559 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/internal/fakeaws"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+// Acceptance tests run Terraform against the provider and a fake of AWS serving
+// testdata/fakeaws.yaml, they only need TF_ACC to be set.
+
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"awsapigateway": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+// testAccFakeAws starts the fake of AWS and returns the provider block pointing at it.
+func testAccFakeAws(t *testing.T) string {
+	server := fakeaws.NewServer(t, "testdata/fakeaws.yaml")
+	server.SetCredentials(t)
+	return fmt.Sprintf(`
+provider "awsapigateway" {
+  endpoints {
+    apigateway   = %[1]q
+    apigatewayv2 = %[1]q
+    sts          = %[1]q
+  }
+}
+`, server.URL)
+}
+
+func TestAccResource(t *testing.T) {
+	providerConfig := testAccFakeAws(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "awsapigateway_resource" "test" {
+  accounts {
+    region                 = "us-east-1"
+    api_list               = ["rest1", "http1"]
+    cross_account_role_arn = ""
+    exclude                = false
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awsapigateway_resource.test", "log_group_names.#", "3"),
+					resource.TestCheckResourceAttr("awsapigateway_resource.test", "log_group_names.0", "API-Gateway-Execution-Logs_rest1/dev"),
+					resource.TestCheckResourceAttr("awsapigateway_resource.test", "log_group_names.1", "orders-access-logs"),
+					resource.TestCheckResourceAttr("awsapigateway_resource.test", "log_group_names.2", "pets-access-logs"),
+					resource.TestCheckResourceAttr("awsapigateway_resource.test", "stage_inventory.#", "2"),
+					resource.TestCheckResourceAttr("awsapigateway_resource.test", "failed_accounts.#", "0"),
+					resource.TestCheckResourceAttrSet("awsapigateway_resource.test", "id"),
+				),
+			},
+			{
+				// the account of the role can't list its REST apis, fail_on lets the other account through
+				Config: providerConfig + `
+resource "awsapigateway_resource" "test" {
+  fail_on = "all_accounts_failed"
+
+  accounts {
+    region                 = "us-east-1"
+    api_list               = ["rest1", "http1"]
+    cross_account_role_arn = ""
+    exclude                = false
+  }
+  accounts {
+    region                 = "eu-west-1"
+    api_list               = []
+    cross_account_role_arn = "arn:aws:iam::210987654321:role/traceable"
+    exclude                = true
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awsapigateway_resource.test", "log_group_names.#", "3"),
+					resource.TestCheckResourceAttr("awsapigateway_resource.test", "failed_accounts.#", "1"),
+					resource.TestCheckResourceAttr("awsapigateway_resource.test", "failed_accounts.0.account_id", "210987654321"),
+					resource.TestCheckResourceAttr("awsapigateway_resource.test", "failed_accounts.0.region", "eu-west-1"),
+				),
+			},
+		},
+	})
+}
+
+// The arguments an import ID leaves out stay unset, the configuration it was taken from
+// plans no change after the import.
+func TestAccResourceImport(t *testing.T) {
+	providerConfig := testAccFakeAws(t)
+	config := providerConfig + `
+resource "awsapigateway_resource" "test" {
+  accounts {
+    region                 = "us-east-1"
+    api_list               = ["rest1", "http1"]
+    cross_account_role_arn = ""
+  }
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:             config,
+				ResourceName:       "awsapigateway_resource.test",
+				ImportState:        true,
+				ImportStateId:      "us-east-1::rest1,http1",
+				ImportStatePersist: true,
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccGovCloud(t *testing.T) {
+	providerConfig := testAccFakeAws(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// roles of the aws partition can't be assumed in GovCloud
+				Config: providerConfig + `
+data "awsapigateway_log_groups" "test" {
+  accounts {
+    region                 = "us-gov-west-1"
+    include_apis           = ["benefits"]
+    cross_account_role_arn = "arn:aws:iam::333333333333:role/traceable"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`cross_account_role_arn is in the aws partition, the account is in aws-us-gov`),
+			},
+			{
+				Config: providerConfig + `
+data "awsapigateway_log_groups" "test" {
+  accounts {
+    region                 = "us-gov-west-1"
+    include_apis           = ["benefits"]
+    cross_account_role_arn = "arn:aws-us-gov:iam::333333333333:role/traceable"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`(?s)REST API Access Logs not enabled for \[gov1/prod\] in account 333333333333.*` +
+					`https://us-gov-west-1\.console\.amazonaws-us-gov\.com/apigateway/main/apis/gov1/stages`),
+			},
+		},
+	})
+}
+
+func TestAccStsRegion(t *testing.T) {
+	providerConfig := testAccFakeAws(t)
+	withStsRegion := strings.Replace(providerConfig, `provider "awsapigateway" {`, `provider "awsapigateway" {
+  sts_region = "eu-central-1"`, 1)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "awsapigateway_log_groups" "test" {
+  accounts {
+    region                 = "us-gov-west-1"
+    include_apis           = ["benefits"]
+    cross_account_role_arn = "arn:aws-us-gov:iam::333333333333:role/traceable"
+    sts_region             = "us-east-1"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`sts_region us-east-1 is in the aws partition, the account is in aws-us-gov`),
+			},
+			{
+				// both accounts assume the role through STS in eu-central-1, the first one in
+				// eu-west-1 can't list its REST apis
+				Config: withStsRegion + `
+data "awsapigateway_log_groups" "test" {
+  fail_on = "all_accounts_failed"
+
+  accounts {
+    region                 = "eu-west-1"
+    exclude_apis           = ["legacy-*"]
+    cross_account_role_arn = "arn:aws:iam::210987654321:role/traceable"
+  }
+  accounts {
+    region                 = "us-east-1"
+    exclude_apis           = ["legacy-*"]
+    cross_account_role_arn = "arn:aws:iam::210987654321:role/traceable"
+    sts_region             = "us-west-2"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "failed_accounts.#", "1"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "failed_accounts.0.account_id", "210987654321"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "failed_accounts.0.region", "eu-west-1"),
+				),
+			},
+		},
+	})
+}
+
+// The resources and data sources of a provider share the responses of AWS for cache_ttl.
+// An empty region of the provider is the default region, it is accepted although the
+// region is validated.
+func TestAccEmptyProviderRegion(t *testing.T) {
+	providerConfig := strings.Replace(testAccFakeAws(t), `provider "awsapigateway" {`, `provider "awsapigateway" {
+  region = ""`, 1)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "awsapigateway_log_groups" "test" {
+  accounts {
+    region                 = "us-east-1"
+    include_apis           = ["pets"]
+    cross_account_role_arn = ""
+  }
+}
+`,
+				Check: resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "log_group_names.#", "2"),
+			},
+		},
+	})
+}
+
+func TestAccCache(t *testing.T) {
+	providerConfig := testAccFakeAws(t)
+	withCacheTtl := func(ttl string) string {
+		return strings.Replace(providerConfig, `provider "awsapigateway" {`, fmt.Sprintf(`provider "awsapigateway" {
+  cache_ttl = %q`, ttl), 1)
+	}
+	accounts := `
+  accounts {
+    region                 = "us-east-1"
+    include_apis           = ["pets", "orders"]
+    cross_account_role_arn = ""
+  }
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      withCacheTtl("2h") + `data "awsapigateway_log_groups" "test" {` + accounts + `}`,
+				ExpectError: regexp.MustCompile(`Invalid cache TTL`),
+			},
+			{
+				Config: withCacheTtl("1m") + `
+resource "awsapigateway_resource" "test" {` + accounts + `}
+data "awsapigateway_log_groups" "test" {` + accounts + `}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awsapigateway_resource.test", "log_group_names.#", "3"),
+					resource.TestCheckResourceAttrPair("awsapigateway_resource.test", "log_group_names.0",
+						"data.awsapigateway_log_groups.test", "log_group_names.0"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "log_group_names.#", "3"),
+				),
+			},
+			{
+				Config: withCacheTtl("0s") + `data "awsapigateway_log_groups" "test" {` + accounts + `}`,
+				Check:  resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "log_group_names.#", "3"),
+			},
+		},
+	})
+}
+
+// The apis and stages of 444444444444 take several pages, every page is read and cached
+// apart from the others.
+func TestAccPaging(t *testing.T) {
+	providerConfig := strings.Replace(testAccFakeAws(t), `provider "awsapigateway" {`, `provider "awsapigateway" {
+  cache_ttl = "1m"`, 1)
+	accounts := `
+  accounts {
+    region                 = "us-west-2"
+    api_list               = []
+    cross_account_role_arn = "arn:aws:iam::444444444444:role/traceable"
+    exclude                = true
+  }
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "awsapigateway_resource" "test" {` + accounts + `}
+data "awsapigateway_log_groups" "test" {` + accounts + `}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awsapigateway_resource.test", "log_group_names.#", "4"),
+					resource.TestCheckResourceAttr("awsapigateway_resource.test", "log_group_names.2", "API-Gateway-Execution-Logs_prest3/prod"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "log_group_names.#", "4"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "stage_inventory.#", "8"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "selection_explain.#", "8"),
+				),
+			},
+		},
+	})
+}
+
+// The resource writes its report at apply only, plans and refreshes leave it alone.
+func TestAccReportPath(t *testing.T) {
+	providerConfig := testAccFakeAws(t)
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	config := providerConfig + fmt.Sprintf(`
+resource "awsapigateway_resource" "test" {
+  report_path = %q
+
+  accounts {
+    region                 = "us-east-1"
+    include_apis           = ["pets"]
+    cross_account_role_arn = ""
+  }
+}
+`, reportPath)
+	reportExists := func(exists bool) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			if _, err := os.Stat(reportPath); exists != (err == nil) {
+				return fmt.Errorf("report %s exists: %t, expected %t", reportPath, err == nil, exists)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				PreConfig: func() {
+					assert.NoFileExists(t, reportPath, "the plan wrote the report")
+				},
+				Config: config,
+				Check:  reportExists(true),
+			},
+			{
+				// nothing changes, the resource is not updated and the report not written again
+				PreConfig: func() {
+					assert.NoError(t, os.Remove(reportPath))
+				},
+				Config: config,
+				Check:  reportExists(false),
+			},
+		},
+	})
+}
+
+func TestAccResourceFindings(t *testing.T) {
+	providerConfig := testAccFakeAws(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "awsapigateway_resource" "test" {
+  accounts {
+    region                 = "us-east-1"
+    api_list               = ["rest2"]
+    cross_account_role_arn = ""
+    exclude                = false
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`REST API Access Logs not enabled for \[rest2/dev\]`),
+			},
+		},
+	})
+}
+
+// An api/stage entry of an exclude list leaves out that stage only, the other stages of
+// the api and the other apis are checked.
+func TestAccSelectionExplain(t *testing.T) {
+	providerConfig := testAccFakeAws(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "awsapigateway_log_groups" "test" {
+  accounts {
+    region                 = "us-east-1"
+    api_list               = ["rest2/dev"]
+    cross_account_role_arn = ""
+    exclude                = true
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "stage_inventory.#", "2"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "selection_explain.#", "3"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "selection_explain.0.api_id", "http1"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "selection_explain.0.api_name", "orders"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "selection_explain.0.selected", "true"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "selection_explain.0.reason", "included, no entry of api_list matches"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "selection_explain.1.api_id", "rest1"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "selection_explain.1.selected", "true"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "selection_explain.2.api_id", "rest2"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "selection_explain.2.stage_name", "dev"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "selection_explain.2.selected", "false"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "selection_explain.2.reason", `excluded by api_list[0] "rest2/dev"`),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUnmatchedSelectors(t *testing.T) {
+	providerConfig := testAccFakeAws(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "awsapigateway_log_groups" "test" {
+  fail_on_unmatched = true
+
+  accounts {
+    region                 = "us-east-1"
+    include_apis           = ["pets", "orders/prod"]
+    cross_account_role_arn = ""
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`api selector matches no API or stage for \[orders/prod\]`),
+			},
+			{
+				// without fail_on_unmatched the entry is a warning
+				Config: providerConfig + `
+data "awsapigateway_log_groups" "test" {
+  accounts {
+    region                 = "us-east-1"
+    include_apis           = ["pets", "orders/prod"]
+    cross_account_role_arn = ""
+  }
+}
+`,
+				Check: resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "log_group_names.#", "2"),
+			},
+		},
+	})
+}
+
+// Invalid arguments are reported by terraform validate, before any call to AWS.
+func TestAccValidation(t *testing.T) {
+	tests := []struct {
+		timeout     string
+		region      string
+		roleArn     string
+		apiEntry    string
+		expectError string
+	}{
+		{timeout: "500ms", region: "us-east-1", apiEntry: "api1", expectError: `Invalid timeout`},
+		{timeout: "1m", region: "us-east1", apiEntry: "api1", expectError: `Invalid region`},
+		{timeout: "1m", region: "us-east-1", roleArn: "traceable", apiEntry: "api1", expectError: `Invalid role ARN`},
+		{timeout: "1m", region: "us-east-1", apiEntry: "api1/dev/extra", expectError: `Invalid api entry`},
+		{timeout: "1m", region: "us-east-1", apiEntry: "orders-[", expectError: `Invalid api entry`},
+	}
+
+	var steps []resource.TestStep
+	for _, test := range tests {
+		steps = append(steps, resource.TestStep{
+			Config: fmt.Sprintf(`
+resource "awsapigateway_resource" "test" {
+  timeout = %q
+  accounts {
+    region                 = %q
+    cross_account_role_arn = %q
+    include_apis           = [%q]
+  }
+}
+`, test.timeout, test.region, test.roleArn, test.apiEntry),
+			ExpectError: regexp.MustCompile(test.expectError),
+		})
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
+
+func TestAccLogGroupsDataSource(t *testing.T) {
+	providerConfig := testAccFakeAws(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "awsapigateway_log_groups" "test" {
+  accounts {
+    region                 = "us-east-1"
+    api_list               = ["rest2"]
+    include_apis           = ["rest1"]
+    cross_account_role_arn = ""
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+			{
+				Config: providerConfig + `
+data "awsapigateway_log_groups" "test" {
+  accounts {
+    region                 = "us-east-1"
+    api_list               = ["rest2"]
+    cross_account_role_arn = ""
+    exclude                = true
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "log_group_names.#", "3"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "stage_inventory.#", "2"),
+				),
+			},
+			{
+				Config: providerConfig + `
+data "awsapigateway_log_groups" "test" {
+  accounts {
+    region                 = "us-east-1"
+    exclude_apis           = ["rest2"]
+    cross_account_role_arn = ""
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "log_group_names.#", "3"),
+					resource.TestCheckResourceAttr("data.awsapigateway_log_groups.test", "stage_inventory.#", "2"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	"github.com/Traceableai/terraform-provider-awsapigateway/provider/keys"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = (*awsApiGatewayLogGroupsDataSource)(nil)
+	_ datasource.DataSourceWithConfigure = (*awsApiGatewayLogGroupsDataSource)(nil)
+)
+
+// awsApiGatewayLogGroupsDataSource runs discovery at plan time without keeping anything in state.
+type awsApiGatewayLogGroupsDataSource struct {
+	providerData *providerData
+}
+
+func NewAwsApiGatewayLogGroupsDataSource() datasource.DataSource {
+	return &awsApiGatewayLogGroupsDataSource{}
+}
+
+func (d *awsApiGatewayLogGroupsDataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = keys.AwsApiGatewayLogGroupsDataSource
+}
+
+func (d *awsApiGatewayLogGroupsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("expected *providerData, got %T", req.ProviderData))
+		return
+	}
+	d.providerData = data
+}
+
+func (d *awsApiGatewayLogGroupsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := map[string]schema.Attribute{
+		keys.Id: schema.StringAttribute{
+			Computed: true,
+		},
+		keys.IgnoreAccessLogSettings: schema.BoolAttribute{
+			Optional: true,
+		},
+		keys.StrictLogGroupFormat: schema.BoolAttribute{
+			Optional: true,
+		},
+		keys.Timeout: schema.StringAttribute{
+			Optional:    true,
+			Description: "Defaults to `1m`.",
+			Validators:  []validator.String{timeoutValidator()},
+		},
+		keys.FindingSeverity: schema.MapAttribute{
+			Optional:    true,
+			ElementType: types.StringType,
+			Validators:  findingSeverityValidators(),
+		},
+		keys.FailOn: schema.StringAttribute{
+			Optional:    true,
+			Description: "Defaults to `any_error`.",
+			Validators:  []validator.String{stringvalidator.OneOf(discovery.FailOnModes...)},
+		},
+		keys.FailOnUnmatched: schema.BoolAttribute{
+			Optional:    true,
+			Description: failOnUnmatchedDescription,
+		},
+		keys.ReportPath: schema.StringAttribute{
+			Optional:    true,
+			Description: "File the report of the discovery is written to each time the data source is read, that is at every plan and refresh.",
+			Validators:  []validator.String{stringvalidator.LengthAtLeast(1)},
+		},
+		keys.ReportFormat: schema.StringAttribute{
+			Optional:    true,
+			Description: "Defaults to `json`.",
+			Validators:  []validator.String{stringvalidator.OneOf(discovery.ReportFormats...)},
+		},
+	}
+	dataSourceResultAttributes(attributes)
+	resp.Schema = schema.Schema{
+		Attributes: attributes,
+		Blocks: map[string]schema.Block{
+			keys.Accounts: dataSourceAccountsBlock(),
+		},
+	}
+}
+
+func (d *awsApiGatewayLogGroupsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data dataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	discoveryConfig := data.discoveryConfig()
+	// data sources have no defaults, unset arguments are given the defaults of the resource
+	if discoveryConfig.Timeout == "" {
+		discoveryConfig.Timeout = "1m"
+	}
+	if discoveryConfig.FailOn == "" {
+		discoveryConfig.FailOn = string(discovery.FailOnAnyError)
+	}
+	if discoveryConfig.ReportFormat == "" {
+		discoveryConfig.ReportFormat = string(discovery.ReportFormatJson)
+	}
+	result, diagnostics := discover(ctx, discoveryConfig, d.providerData)
+	resp.Diagnostics.Append(diagnostics...)
+	if result == nil {
+		return
+	}
+	// data sources are only read, the report is written at every plan and refresh
+	resp.Diagnostics.Append(writeReport(result, discoveryConfig)...)
+
+	// the id only depends on the accounts, so that it is stable across reads
+	accounts, err := json.Marshal(discoveryConfig.Accounts)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to compute the data source id", err.Error())
+		return
+	}
+	data.Id = types.StringValue(fmt.Sprintf("%x", sha256.Sum256(accounts)))
+	resp.Diagnostics.Append(data.setDiscoveryResults(ctx, result)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
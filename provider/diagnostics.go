@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	"github.com/Traceableai/terraform-provider-awsapigateway/provider/keys"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// maxListedValues is the number of stages listed in the summary of a finding.
+const maxListedValues = 5
+
+// discover runs discovery and turns the findings into diagnostics, callers write the report.
+func discover(ctx context.Context, discoveryConfig *discovery.Config, data *providerData) (*discovery.Result, diag.Diagnostics) {
+	var diagnostics diag.Diagnostics
+	opts, err := discoveryConfig.Options()
+	if err != nil {
+		diagnostics.AddAttributeError(path.Root(keys.Timeout), "Invalid timeout", err.Error())
+		return nil, diagnostics
+	}
+	opts.ClientFactory = clientFactoryOf(data)
+	if data != nil {
+		opts.Partition = data.partition
+		opts.StsRegion = data.stsRegion
+	}
+	specs := discoveryConfig.Specs()
+	result, err := discovery.Discover(ctx, specs, opts)
+	var specErr *discovery.SpecError
+	if errors.As(err, &specErr) {
+		diagnostics.AddAttributeError(path.Root(keys.Accounts).AtListIndex(specErr.Index), specErr.Message, "")
+		return nil, diagnostics
+	} else if err != nil {
+		diagnostics.AddError("Unable to discover the log groups", err.Error())
+		return nil, diagnostics
+	}
+
+	diagnostics.Append(findingDiagnostics(result, opts.FailOn.Fails(len(result.FailedAccounts), len(specs)))...)
+	return result, diagnostics
+}
+
+func writeReport(result *discovery.Result, discoveryConfig *discovery.Config) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	if discoveryConfig.ReportPath == "" {
+		return diagnostics
+	}
+	if err := result.WriteReport(discoveryConfig.ReportPath, discovery.ReportFormat(discoveryConfig.ReportFormat)); err != nil {
+		diagnostics.AddAttributeError(path.Root(keys.ReportPath), "Unable to write the report to "+discoveryConfig.ReportPath, err.Error())
+	}
+	return diagnostics
+}
+
+// findingKey groups the stages sharing a summary, an account and a selector entry.
+type findingKey struct {
+	severity     discovery.FindingSeverity
+	summary      string
+	accountIndex int
+	selectorList string
+	apiListIndex int
+}
+
+type findingGroup struct {
+	findingKey
+	findings []discovery.Finding
+}
+
+// findingDiagnostics groups findings by summary, account and api_list entry. Failed accounts
+// are errors when failAccounts is set, warnings otherwise.
+func findingDiagnostics(result *discovery.Result, failAccounts bool) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	var groups []*findingGroup
+	for _, finding := range result.AllFindings() {
+		if finding.Severity == discovery.FindingSeverityIgnore {
+			continue
+		}
+		if finding.Value == "" {
+			diagnostics.Append(findingDiagnostic(finding.Severity, finding.AccountIndex, "", -1, finding.Message, finding.Detail))
+			continue
+		}
+		key := findingKey{
+			severity:     finding.Severity,
+			summary:      finding.Summary,
+			accountIndex: finding.AccountIndex,
+			selectorList: finding.SelectorList,
+			apiListIndex: finding.ApiListIndex,
+		}
+		group := groupOf(groups, key)
+		if group == nil {
+			group = &findingGroup{findingKey: key}
+			groups = append(groups, group)
+		}
+		group.findings = append(group.findings, finding)
+	}
+
+	for _, failedAccount := range result.FailedAccounts {
+		severity := discovery.FindingSeverityWarning
+		if failAccounts {
+			severity = discovery.FindingSeverityError
+		}
+		summary := fmt.Sprintf("%s in %s", failedAccount.Error, discovery.AccountName(failedAccount.AccountId, failedAccount.Region))
+		diagnostics.Append(findingDiagnostic(severity, failedAccount.AccountIndex, "", -1, summary, ""))
+	}
+	for _, severity := range []discovery.FindingSeverity{discovery.FindingSeverityWarning, discovery.FindingSeverityError} {
+		for _, group := range groups {
+			if group.severity == severity {
+				diagnostics.Append(group.diagnostic())
+			}
+		}
+	}
+	return diagnostics
+}
+
+func groupOf(groups []*findingGroup, key findingKey) *findingGroup {
+	for _, group := range groups {
+		if group.findingKey == key {
+			return group
+		}
+	}
+	return nil
+}
+
+func (g *findingGroup) diagnostic() diag.Diagnostic {
+	first := g.findings[0]
+	values := make([]string, 0, len(g.findings))
+	for _, finding := range g.findings {
+		values = append(values, finding.Value)
+	}
+	listed := values
+	if len(values) > maxListedValues {
+		listed = append(values[:maxListedValues:maxListedValues], fmt.Sprintf("and %d more", len(values)-maxListedValues))
+	}
+	summary := fmt.Sprintf("%s for %s", g.summary, discovery.StringFromArray(listed))
+	if first.Region != "" {
+		summary = fmt.Sprintf("%s in %s", summary, discovery.AccountName(first.AccountId, first.Region))
+	}
+
+	var detail []string
+	if first.Detail != "" {
+		detail = append(detail, first.Detail)
+	}
+	var links []string
+	for _, finding := range g.findings {
+		if finding.ConsoleUrl != "" {
+			links = append(links, fmt.Sprintf("%s: %s", finding.Value, finding.ConsoleUrl))
+		}
+	}
+	if len(links) > 0 {
+		if len(detail) > 0 {
+			detail = append(detail, "")
+		}
+		detail = append(detail, links...)
+	}
+	return findingDiagnostic(g.severity, g.accountIndex, g.selectorList, g.apiListIndex, summary, strings.Join(detail, "\n"))
+}
+
+// findingDiagnostic points a diagnostic at the account or the selector entry it is about.
+func findingDiagnostic(severity discovery.FindingSeverity, accountIndex int, selectorList string, apiListIndex int, summary string, detail string) diag.Diagnostic {
+	var diagnostic diag.Diagnostic = diag.NewErrorDiagnostic(summary, detail)
+	if severity == discovery.FindingSeverityWarning {
+		diagnostic = diag.NewWarningDiagnostic(summary, detail)
+	}
+	if accountIndex < 0 {
+		return diagnostic
+	}
+	attributePath := path.Root(keys.Accounts).AtListIndex(accountIndex)
+	if apiListIndex >= 0 {
+		if selectorList == "" {
+			selectorList = keys.ApiList
+		}
+		attributePath = attributePath.AtName(selectorList).AtListIndex(apiListIndex)
+	}
+	return diag.WithPath(attributePath, diagnostic)
+}
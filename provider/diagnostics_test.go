@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindingDiagnosticsGrouping(t *testing.T) {
+	finding := func(accountIndex int, accountId string, region string, summary string, value string, apiListIndex int) discovery.Finding {
+		return discovery.Finding{
+			AccountIndex: accountIndex,
+			AccountId:    accountId,
+			Region:       region,
+			Severity:     discovery.FindingSeverityError,
+			Summary:      summary,
+			Value:        value,
+			Message:      summary + " for " + value,
+			Detail:       "remediation",
+			ApiListIndex: apiListIndex,
+			ConsoleUrl:   "https://" + region + ".console.aws.amazon.com/apigateway/main/apis/" + value,
+		}
+	}
+	wrongSyntax := finding(1, "123456789012", "us-east-1", "api gateway syntax is wrong", "a/b/c", 2)
+	wrongSyntax.ConsoleUrl = ""
+	override := finding(1, "123456789012", "us-east-1", "Execution Log settings overridden", "api1/dev /pets/GET (ERROR)", 0)
+	override.Severity = discovery.FindingSeverityWarning
+	ignored := finding(1, "123456789012", "us-east-1", "Execution Logs not enabled", "api1/dev", 0)
+	ignored.Severity = discovery.FindingSeverityIgnore
+	result := &discovery.Result{
+		Findings: []discovery.Finding{
+			wrongSyntax,
+			{AccountIndex: 1, Region: "us-east-1", Severity: discovery.FindingSeverityWarning, Message: "conflicting keys", Detail: "detail", ApiListIndex: -1},
+		},
+		Stages: []discovery.StageResult{
+			{Findings: []discovery.Finding{finding(1, "123456789012", "us-east-1", "REST API Access Logs not enabled", "api1/dev", 0), override, ignored}},
+			{Findings: []discovery.Finding{finding(1, "123456789012", "us-east-1", "REST API Access Logs not enabled", "api1/prod", 0)}},
+			{Findings: []discovery.Finding{finding(1, "123456789012", "us-east-1", "REST API Access Logs not enabled", "api2/dev", 1)}},
+		},
+	}
+	for _, stage := range []string{"s1", "s2", "s3", "s4", "s5", "s6", "s7"} {
+		result.Stages = append(result.Stages, discovery.StageResult{
+			Findings: []discovery.Finding{finding(2, "210987654321", "eu-west-1", "Execution Logs not enabled", "api3/"+stage, -1)},
+		})
+	}
+
+	diagnostics := findingDiagnostics(result, true)
+	assert.Len(t, diagnostics, 6)
+
+	type expectedDiagnostic struct {
+		severity diag.Severity
+		summary  string
+		path     path.Path
+	}
+	var actual []expectedDiagnostic
+	for _, diagnostic := range diagnostics {
+		withPath, ok := diagnostic.(diag.DiagnosticWithPath)
+		assert.True(t, ok)
+		actual = append(actual, expectedDiagnostic{severity: diagnostic.Severity(), summary: diagnostic.Summary(), path: withPath.Path()})
+	}
+	accounts := path.Root("accounts")
+	assert.Equal(t, []expectedDiagnostic{
+		{
+			severity: diag.SeverityWarning,
+			summary:  "conflicting keys",
+			path:     accounts.AtListIndex(1),
+		},
+		{
+			severity: diag.SeverityWarning,
+			summary:  "Execution Log settings overridden for [api1/dev /pets/GET (ERROR)] in account 123456789012 (us-east-1)",
+			path:     accounts.AtListIndex(1).AtName("api_list").AtListIndex(0),
+		},
+		{
+			severity: diag.SeverityError,
+			summary:  "api gateway syntax is wrong for [a/b/c] in account 123456789012 (us-east-1)",
+			path:     accounts.AtListIndex(1).AtName("api_list").AtListIndex(2),
+		},
+		{
+			severity: diag.SeverityError,
+			summary:  "REST API Access Logs not enabled for [api1/dev, api1/prod] in account 123456789012 (us-east-1)",
+			path:     accounts.AtListIndex(1).AtName("api_list").AtListIndex(0),
+		},
+		{
+			severity: diag.SeverityError,
+			summary:  "REST API Access Logs not enabled for [api2/dev] in account 123456789012 (us-east-1)",
+			path:     accounts.AtListIndex(1).AtName("api_list").AtListIndex(1),
+		},
+		{
+			severity: diag.SeverityError,
+			summary:  "Execution Logs not enabled for [api3/s1, api3/s2, api3/s3, api3/s4, api3/s5, and 2 more] in account 210987654321 (eu-west-1)",
+			path:     accounts.AtListIndex(2),
+		},
+	}, actual)
+
+	assert.Equal(t, "remediation\n\n"+
+		"api1/dev: https://us-east-1.console.aws.amazon.com/apigateway/main/apis/api1/dev\n"+
+		"api1/prod: https://us-east-1.console.aws.amazon.com/apigateway/main/apis/api1/prod",
+		diagnostics[3].Detail())
+	assert.Contains(t, diagnostics[5].Detail(), "api3/s7: https://eu-west-1.console.aws.amazon.com/apigateway/main/apis/api3/s7")
+	assert.Equal(t, "remediation", diagnostics[2].Detail())
+}
+
+func TestFindingDiagnosticsSelectorList(t *testing.T) {
+	result := &discovery.Result{
+		Stages: []discovery.StageResult{{Findings: []discovery.Finding{{
+			AccountIndex: 0,
+			Region:       "us-east-1",
+			Severity:     discovery.FindingSeverityError,
+			Summary:      "Execution Logs not enabled",
+			Value:        "orders/dev",
+			SelectorList: "include_apis",
+			ApiListIndex: 1,
+		}}}},
+	}
+
+	diagnostics := findingDiagnostics(result, true)
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, path.Root("accounts").AtListIndex(0).AtName("include_apis").AtListIndex(1), diagnostics[0].(diag.DiagnosticWithPath).Path())
+}
+
+func TestFindingDiagnosticsFailedAccounts(t *testing.T) {
+	result := &discovery.Result{
+		FailedAccounts: []discovery.FailedAccount{{
+			AccountIndex: 0,
+			AccountId:    "123456789012",
+			Region:       "us-east-1",
+			Error:        "Error while invoking getRestApis sdk call: access denied",
+		}},
+	}
+
+	for _, failAccounts := range []bool{true, false} {
+		t.Run(fmt.Sprint(failAccounts), func(t *testing.T) {
+			diagnostics := findingDiagnostics(result, failAccounts)
+			assert.Len(t, diagnostics, 1)
+			assert.Equal(t, failAccounts, diagnostics.HasError())
+			assert.Equal(t, "Error while invoking getRestApis sdk call: access denied in account 123456789012 (us-east-1)", diagnostics[0].Summary())
+			assert.Equal(t, path.Root("accounts").AtListIndex(0), diagnostics[0].(diag.DiagnosticWithPath).Path())
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	"github.com/Traceableai/terraform-provider-awsapigateway/provider/keys"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Provider functions expose the naming and access log format rules used by discovery, so
+// that modules don't have to re-implement them. They need Terraform 1.8 or later.
+
+var (
+	_ function.Function = (*executionLogGroupNameFunction)(nil)
+	_ function.Function = (*accessLogGroupFromArnFunction)(nil)
+	_ function.Function = (*parseAccessLogFormatFunction)(nil)
+	_ function.Function = (*validateAccessLogFormatFunction)(nil)
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//                         execution_log_group_name                          //
+///////////////////////////////////////////////////////////////////////////////
+
+type executionLogGroupNameFunction struct{}
+
+func NewExecutionLogGroupNameFunction() function.Function {
+	return &executionLogGroupNameFunction{}
+}
+
+func (f *executionLogGroupNameFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = keys.ExecutionLogGroupNameFunction
+}
+
+func (f *executionLogGroupNameFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Execution log group name of a REST API stage",
+		Description: "Returns the name of the log group API Gateway writes the execution logs of a REST API stage to.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: keys.ApiId, Description: "ID of the REST API."},
+			function.StringParameter{Name: keys.StageName, Description: "Name of the stage."},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *executionLogGroupNameFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var apiId, stageName string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &apiId, &stageName))
+	if resp.Error != nil {
+		return
+	}
+	if apiId == "" || stageName == "" {
+		resp.Error = function.NewFuncError("api_id and stage_name must not be empty")
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, discovery.ExecutionLogGroupName(apiId, stageName)))
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                         access_log_group_from_arn                         //
+///////////////////////////////////////////////////////////////////////////////
+
+type accessLogGroupFromArnFunction struct{}
+
+func NewAccessLogGroupFromArnFunction() function.Function {
+	return &accessLogGroupFromArnFunction{}
+}
+
+func (f *accessLogGroupFromArnFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = keys.AccessLogGroupFromArnFunction
+}
+
+func (f *accessLogGroupFromArnFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Access log group name from its ARN",
+		Description: "Returns the name of the CloudWatch Logs log group of an access log destination ARN.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "arn", Description: "ARN of the log group, as set in the access log settings of a stage."},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *accessLogGroupFromArnFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var arn string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &arn))
+	if resp.Error != nil {
+		return
+	}
+	if err := discovery.ValidateLogGroupArn(arn); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, discovery.AccessLogGroupNameFromArn(arn)))
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                          parse_access_log_format                          //
+///////////////////////////////////////////////////////////////////////////////
+
+type parseAccessLogFormatFunction struct{}
+
+func NewParseAccessLogFormatFunction() function.Function {
+	return &parseAccessLogFormatFunction{}
+}
+
+func (f *parseAccessLogFormatFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = keys.ParseAccessLogFormatFunction
+}
+
+func (f *parseAccessLogFormatFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Keys of the variables of a JSON access log format",
+		Description: "Parses a JSON access log format the way discovery does, quoting bare variables first, and returns " +
+			"the key of every `$context` and `$stageVariables` variable. Nested keys are joined with dots.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "format", Description: "Access log format of a stage."},
+		},
+		Return: function.MapReturn{ElementType: types.StringType},
+	}
+}
+
+func (f *parseAccessLogFormatFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var format string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &format))
+	if resp.Error != nil {
+		return
+	}
+	variableKeys, _, err := discovery.AnalyzeAccessLogFormat(format, nil)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, variableKeys))
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                        validate_access_log_format                         //
+///////////////////////////////////////////////////////////////////////////////
+
+type validateAccessLogFormatFunction struct{}
+
+func NewValidateAccessLogFormatFunction() function.Function {
+	return &validateAccessLogFormatFunction{}
+}
+
+func (f *validateAccessLogFormatFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = keys.ValidateAccessLogFormatFunction
+}
+
+func (f *validateAccessLogFormatFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Checks an access log format",
+		Description: "Returns true if the access log format is JSON parsable and holds every required variable, so " +
+			"that it can be used in a validation condition. A null list requires the variables discovery requires " +
+			"for REST and HTTP APIs.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "format", Description: "Access log format of a stage."},
+			function.ListParameter{
+				Name:           "required",
+				Description:    "Variables the format must hold, such as `$context.status`.",
+				ElementType:    types.StringType,
+				AllowNullValue: true,
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *validateAccessLogFormatFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var format string
+	var required []string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &format, &required))
+	if resp.Error != nil {
+		return
+	}
+	if required == nil {
+		required = discovery.AccessLogFormatMandatoryValues
+	}
+	for i, value := range required {
+		if variables := discovery.ParseAccessLogFormat(value).Variables(); len(variables) != 1 || variables[0].Text != value {
+			resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("required[%d] %q is not an access log variable", i, value))
+			return
+		}
+	}
+	_, missingValues, err := discovery.AnalyzeAccessLogFormat(format, required)
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, err == nil && len(missingValues) == 0))
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func runFunction(f function.Function, result attr.Value, arguments ...attr.Value) (attr.Value, *function.FuncError) {
+	resp := &function.RunResponse{Result: function.NewResultData(result)}
+	f.Run(context.Background(), function.RunRequest{Arguments: function.NewArgumentsData(arguments)}, resp)
+	return resp.Result.Value(), resp.Error
+}
+
+func TestExecutionLogGroupNameFunction(t *testing.T) {
+	result, err := runFunction(NewExecutionLogGroupNameFunction(), types.StringUnknown(),
+		types.StringValue("api1"), types.StringValue("dev"))
+	assert.Nil(t, err)
+	assert.Equal(t, types.StringValue("API-Gateway-Execution-Logs_api1/dev"), result)
+
+	_, err = runFunction(NewExecutionLogGroupNameFunction(), types.StringUnknown(),
+		types.StringValue("api1"), types.StringValue(""))
+	assert.NotNil(t, err)
+}
+
+func TestAccessLogGroupFromArnFunction(t *testing.T) {
+	tests := []struct {
+		name     string
+		arn      string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "log group",
+			arn:      "arn:aws:logs:us-east-1:123456789012:log-group:access-logs",
+			expected: "access-logs",
+		},
+		{
+			name:     "log group with colon",
+			arn:      "arn:aws-us-gov:logs:us-gov-west-1:123456789012:log-group:team:access-logs",
+			expected: "team:access-logs",
+		},
+		{
+			name:     "china",
+			arn:      "arn:aws-cn:logs:cn-north-1:123456789012:log-group:access-logs",
+			expected: "access-logs",
+		},
+		{
+			name: "unknown partition",
+			arn:  "arn:aws-eu:logs:eu-west-1:123456789012:log-group:access-logs",
+			err:  true,
+		},
+		{
+			name: "firehose",
+			arn:  "arn:aws:firehose:us-east-1:123456789012:deliverystream/amazon-apigateway-logs",
+			err:  true,
+		},
+		{
+			name: "not an arn",
+			arn:  "access-logs",
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := runFunction(NewAccessLogGroupFromArnFunction(), types.StringUnknown(), types.StringValue(test.arn))
+			if test.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, types.StringValue(test.expected), result)
+		})
+	}
+}
+
+func TestParseAccessLogFormatFunction(t *testing.T) {
+	result, err := runFunction(NewParseAccessLogFormatFunction(), types.MapUnknown(types.StringType),
+		types.StringValue(`{"requestId": $context.requestId, "http": {"method": "$context.httpMethod"}, "route": "$context.path?$context.stage"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, types.MapValueMust(types.StringType, map[string]attr.Value{
+		"$context.requestId":  types.StringValue("requestId"),
+		"$context.httpMethod": types.StringValue("http.method"),
+		"$context.path":       types.StringValue("route"),
+		"$context.stage":      types.StringValue("route"),
+	}), result)
+
+	_, err = runFunction(NewParseAccessLogFormatFunction(), types.MapUnknown(types.StringType),
+		types.StringValue(`$context.requestId $context.httpMethod`))
+	assert.NotNil(t, err)
+}
+
+func TestValidateAccessLogFormatFunction(t *testing.T) {
+	valid := `{"method": "$context.httpMethod", "domain": "$context.domainName", "status": "$context.status", "path": "$context.path"}`
+	tests := []struct {
+		name     string
+		format   string
+		required attr.Value
+		expected bool
+		err      bool
+	}{
+		{
+			name:     "default required values",
+			format:   valid,
+			required: types.ListNull(types.StringType),
+			expected: true,
+		},
+		{
+			name:     "missing default required values",
+			format:   `{"status": "$context.status"}`,
+			required: types.ListNull(types.StringType),
+		},
+		{
+			name:     "given required values",
+			format:   `{"status": "$context.status"}`,
+			required: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("$context.status")}),
+			expected: true,
+		},
+		{
+			name:     "not JSON",
+			format:   `$context.status`,
+			required: types.ListValueMust(types.StringType, []attr.Value{}),
+		},
+		{
+			name:     "invalid required value",
+			format:   valid,
+			required: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("status")}),
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := runFunction(NewValidateAccessLogFormatFunction(), types.BoolUnknown(),
+				types.StringValue(test.format), test.required)
+			if test.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, types.BoolValue(test.expected), result)
+		})
+	}
+}
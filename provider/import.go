@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+type importConfig struct {
+	discovery.Config
+	Accounts []importAccount `json:"accounts"`
+}
+
+// importAccount leaves ApiList and Exclude nil when the import ID leaves them out.
+type importAccount struct {
+	discovery.AccountConfig
+	Exclude *bool `json:"exclude"`
+}
+
+func (c *importConfig) discoveryConfig() *discovery.Config {
+	discoveryConfig := c.Config
+	discoveryConfig.Accounts = nil
+	for _, account := range c.Accounts {
+		accountConfig := account.AccountConfig
+		accountConfig.Exclude = account.Exclude != nil && *account.Exclude
+		discoveryConfig.Accounts = append(discoveryConfig.Accounts, accountConfig)
+	}
+	return &discoveryConfig
+}
+
+// parseImportId reads the configuration of the resource from an import ID, which can be
+//   - a JSON object with the resource arguments, e.g. {"accounts":[...],"timeout":"1m"}
+//   - a JSON array of accounts
+//   - @path to a file holding either of the above
+//   - region:cross_account_role_arn:api_list[:exclude] with comma separated apis, several
+//     accounts are separated by semicolons and the role arn may be empty
+func parseImportId(id string) (*importConfig, error) {
+	id = strings.TrimSpace(id)
+	if strings.HasPrefix(id, "@") {
+		content, err := os.ReadFile(strings.TrimPrefix(id, "@"))
+		if err != nil {
+			return nil, fmt.Errorf("reading import file: %w", err)
+		}
+		id = strings.TrimSpace(string(content))
+	}
+
+	imported := &importConfig{}
+	var err error
+	switch {
+	case strings.HasPrefix(id, "{"):
+		err = decodeImportJson(id, imported)
+	case strings.HasPrefix(id, "["):
+		err = decodeImportJson(id, &imported.Accounts)
+	default:
+		imported.Accounts, err = parseAccountConfigs(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(imported.Accounts) == 0 {
+		return nil, fmt.Errorf("import id %q has no accounts", id)
+	}
+	for i, account := range imported.Accounts {
+		if account.Region == "" {
+			return nil, fmt.Errorf("account %d of the import id has no region", i)
+		}
+		if err := account.Validate(); err != nil {
+			return nil, fmt.Errorf("account %d of the import id: %w", i, err)
+		}
+	}
+	if err := imported.Validate(); err != nil {
+		return nil, fmt.Errorf("import id: %w", err)
+	}
+	if imported.FailOn == "" {
+		imported.FailOn = string(discovery.FailOnAnyError)
+	}
+	if imported.ReportFormat == "" {
+		imported.ReportFormat = string(discovery.ReportFormatJson)
+	}
+	if imported.Timeout == "" {
+		imported.Timeout = "1m"
+	}
+	return imported, nil
+}
+
+func decodeImportJson(data string, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewBufferString(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("parsing import id as JSON: %w", err)
+	}
+	return nil
+}
+
+// parseAccountConfigs reads region:cross_account_role_arn:api_list[:exclude] accounts, a role
+// ARN has six colon separated parts.
+func parseAccountConfigs(id string) ([]importAccount, error) {
+	var accounts []importAccount
+	for _, accountId := range strings.Split(id, ";") {
+		syntaxErr := fmt.Errorf("import id %q is not region:cross_account_role_arn:api_list[:exclude]", accountId)
+		parts := strings.Split(accountId, ":")
+		if len(parts) < 3 {
+			return nil, syntaxErr
+		}
+		roleArn, rest := "", parts[2:]
+		if parts[1] != "" {
+			if parts[1] != "arn" || len(parts) < 8 {
+				return nil, syntaxErr
+			}
+			roleArn, rest = strings.Join(parts[1:7], ":"), parts[7:]
+		}
+		var exclude *bool
+		switch {
+		case len(rest) == 2 && (rest[1] == string(discovery.ApiGatewayActionInclude) || rest[1] == string(discovery.ApiGatewayActionExclude)):
+			exclude = aws.Bool(rest[1] == string(discovery.ApiGatewayActionExclude))
+		case len(rest) != 1:
+			return nil, syntaxErr
+		}
+		apiList := []string{}
+		if rest[0] != "" {
+			apiList = strings.Split(rest[0], ",")
+		}
+		accounts = append(accounts, importAccount{
+			AccountConfig: discovery.AccountConfig{
+				Region:              parts[0],
+				ApiList:             apiList,
+				CrossAccountRoleArn: roleArn,
+			},
+			Exclude: exclude,
+		})
+	}
+	return accounts, nil
+}
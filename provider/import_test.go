@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImportId(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *discovery.Config
+		err      bool
+	}{
+		{
+			name:  "single account",
+			input: "us-east-1::api1,api2/dev",
+			expected: &discovery.Config{
+				Timeout:      "1m",
+				FailOn:       "any_error",
+				ReportFormat: "json",
+				Accounts:     []discovery.AccountConfig{{Region: "us-east-1", ApiList: []string{"api1", "api2/dev"}}},
+			},
+		},
+		{
+			name:  "accounts with role arns and exclude",
+			input: "us-east-1:arn:aws:iam::123456789012:role/traceable:api1:exclude;eu-west-1:arn:aws:iam::210987654321:role/traceable::exclude",
+			expected: &discovery.Config{
+				Timeout:      "1m",
+				FailOn:       "any_error",
+				ReportFormat: "json",
+				Accounts: []discovery.AccountConfig{
+					{Region: "us-east-1", ApiList: []string{"api1"}, CrossAccountRoleArn: "arn:aws:iam::123456789012:role/traceable", Exclude: true},
+					{Region: "eu-west-1", ApiList: []string{}, CrossAccountRoleArn: "arn:aws:iam::210987654321:role/traceable", Exclude: true},
+				},
+			},
+		},
+		{
+			name:  "json object",
+			input: `{"timeout":"10s","strict_log_group_format":true,"fail_on":"never","accounts":[{"region":"us-east-1","api_list":["api1"],"cross_account_role_arn":"","exclude":false}]}`,
+			expected: &discovery.Config{
+				StrictLogGroupFormat: true,
+				Timeout:              "10s",
+				FailOn:               "never",
+				ReportFormat:         "json",
+				Accounts:             []discovery.AccountConfig{{Region: "us-east-1", ApiList: []string{"api1"}}},
+			},
+		},
+		{
+			name:  "json array",
+			input: `[{"region":"us-east-1","api_list":["api1"],"exclude":true}]`,
+			expected: &discovery.Config{
+				Timeout:      "1m",
+				FailOn:       "any_error",
+				ReportFormat: "json",
+				Accounts:     []discovery.AccountConfig{{Region: "us-east-1", ApiList: []string{"api1"}, Exclude: true}},
+			},
+		},
+		{
+			name:  "json with unknown field",
+			input: `{"accounts":[{"region":"us-east-1","apis":["api1"]}]}`,
+			err:   true,
+		},
+		{
+			name:  "json without region",
+			input: `[{"api_list":["api1"]}]`,
+			err:   true,
+		},
+		{
+			name:  "api named exclude",
+			input: "us-east-1::exclude",
+			expected: &discovery.Config{
+				Timeout:      "1m",
+				FailOn:       "any_error",
+				ReportFormat: "json",
+				Accounts:     []discovery.AccountConfig{{Region: "us-east-1", ApiList: []string{"exclude"}}},
+			},
+		},
+		{
+			name:  "missing api list",
+			input: "us-east-1",
+			err:   true,
+		},
+		{
+			name:  "role arn without api list",
+			input: "us-east-1:arn:aws:iam::123456789012:role/traceable",
+			err:   true,
+		},
+		{
+			name:  "extra part after the api list",
+			input: "us-east-1::api1:api2",
+			err:   true,
+		},
+		{
+			name:  "invalid region",
+			input: "us-east1::api1",
+			err:   true,
+		},
+		{
+			name:  "invalid role arn",
+			input: "us-east-1:arn:aws:s3:::bucket:api1",
+			err:   true,
+		},
+		{
+			name:  "invalid api entry",
+			input: "us-east-1::api1/dev/extra",
+			err:   true,
+		},
+		{
+			name:  "json with invalid include_apis",
+			input: `[{"region":"us-east-1","include_apis":["orders-["]}]`,
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			imported, err := parseImportId(test.input)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, imported.discoveryConfig())
+		})
+	}
+}
+
+func TestParseImportIdFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`[{"region":"us-east-1","api_list":["api1"]}]`), 0o600))
+
+	imported, err := parseImportId("@" + path)
+	assert.NoError(t, err)
+	assert.Equal(t, []discovery.AccountConfig{{Region: "us-east-1", ApiList: []string{"api1"}}}, imported.discoveryConfig().Accounts)
+}
+
+func TestResourceImport(t *testing.T) {
+	ctx := context.Background()
+	s := resourceSchema()
+	resp := &resource.ImportStateResponse{
+		State: tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)},
+	}
+	NewAwsApiGatewayResource().(resource.ResourceWithImportState).ImportState(ctx, resource.ImportStateRequest{
+		ID: "us-east-1:arn:aws:iam::123456789012:role/traceable:api1,api2:exclude",
+	}, resp)
+	assert.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+
+	var state resourceModel
+	assert.False(t, resp.State.Get(ctx, &state).HasError())
+	assert.NotEmpty(t, state.Id.ValueString())
+	assert.Equal(t, "1m", state.Timeout.ValueString())
+	assert.Equal(t, []discovery.AccountConfig{{
+		Region:              "us-east-1",
+		ApiList:             []string{"api1", "api2"},
+		CrossAccountRoleArn: "arn:aws:iam::123456789012:role/traceable",
+		Exclude:             true,
+	}}, state.discoveryConfig().Accounts)
+	assert.True(t, state.LogGroupNames.IsNull())
+}
+
+// Arguments that the import ID leaves out stay null, so that a configuration leaving them
+// out too plans no change after the import.
+func TestResourceImportUnsetArguments(t *testing.T) {
+	tests := []struct {
+		id      string
+		apiList bool
+		exclude bool
+	}{
+		{id: "us-east-1::api1", apiList: true},
+		{id: "us-east-1::api1:include", apiList: true, exclude: true},
+		{id: `[{"region":"us-east-1","include_apis":["api1"]}]`},
+		{id: `[{"region":"us-east-1","api_list":[],"exclude":true}]`, apiList: true, exclude: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			imported, err := parseImportId(test.id)
+			assert.NoError(t, err)
+			account := newResourceModel(imported).Accounts[0]
+			assert.Equal(t, test.apiList, account.ApiList != nil)
+			assert.Equal(t, test.exclude, !account.Exclude.IsNull())
+		})
+	}
+}
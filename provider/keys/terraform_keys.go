@@ -1,17 +1,57 @@
 package keys
 
 const (
-	Identifier              = "identifier"
-	IgnoreAccessLogSettings = "ignore_access_log_settings"
-	LogGroupNames           = "log_group_names"
-	Accounts                = "accounts"
-	Region                  = "region"
-	ApiList                 = "api_list"
-	CrossAccountRoleArn     = "cross_account_role_arn"
-	Exclude                 = "exclude"
-	AwsApiGatewayResource   = "awsapigateway_resource"
-	AssumeRole              = "assume_role"
-	Profile                 = "profile"
-	RoleArn                 = "role_arn"
-	Timeout                 = "timeout"
+	Id                               = "id"
+	Identifier                       = "identifier"
+	IgnoreAccessLogSettings          = "ignore_access_log_settings"
+	LogGroupNames                    = "log_group_names"
+	Accounts                         = "accounts"
+	Region                           = "region"
+	ApiList                          = "api_list"
+	IncludeApis                      = "include_apis"
+	ExcludeApis                      = "exclude_apis"
+	CrossAccountRoleArn              = "cross_account_role_arn"
+	Exclude                          = "exclude"
+	AwsApiGatewayResource            = "awsapigateway_resource"
+	AwsApiGatewayLogGroupsDataSource = "awsapigateway_log_groups"
+	AssumeRole                       = "assume_role"
+	Profile                          = "profile"
+	Partition                        = "partition"
+	StsRegion                        = "sts_region"
+	CacheTtl                         = "cache_ttl"
+	AccountsUseProviderCredentials   = "accounts_use_provider_credentials"
+	RoleArn                          = "role_arn"
+	Timeout                          = "timeout"
+	StrictLogGroupFormat             = "strict_log_group_format"
+	StageInventory                   = "stage_inventory"
+	ApiId                            = "api_id"
+	StageName                        = "stage_name"
+	ApiType                          = "api_type"
+	ExecutionLogging                 = "execution_logging"
+	AccessLogGroup                   = "access_log_group"
+	MethodOverrides                  = "method_overrides"
+	Method                           = "method"
+	LoggingLevel                     = "logging_level"
+	DataTraceEnabled                 = "data_trace_enabled"
+
+	ExecutionLogGroupNameFunction   = "execution_log_group_name"
+	AccessLogGroupFromArnFunction   = "access_log_group_from_arn"
+	ParseAccessLogFormatFunction    = "parse_access_log_format"
+	ValidateAccessLogFormatFunction = "validate_access_log_format"
+	FindingSeverity                 = "finding_severity"
+	FailOn                          = "fail_on"
+	FailOnUnmatched                 = "fail_on_unmatched"
+	FailedAccounts                  = "failed_accounts"
+	AccountId                       = "account_id"
+	Error                           = "error"
+	ReportPath                      = "report_path"
+	ReportFormat                    = "report_format"
+	Endpoints                       = "endpoints"
+	ApiGateway                      = "apigateway"
+	ApiGatewayV2                    = "apigatewayv2"
+	Sts                             = "sts"
+	SelectionExplain                = "selection_explain"
+	ApiName                         = "api_name"
+	Selected                        = "selected"
+	Reason                          = "reason"
 )
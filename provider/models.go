@@ -0,0 +1,301 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	"github.com/Traceableai/terraform-provider-awsapigateway/provider/keys"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	methodOverrideAttrTypes = map[string]attr.Type{
+		keys.Method:           types.StringType,
+		keys.LoggingLevel:     types.StringType,
+		keys.DataTraceEnabled: types.BoolType,
+	}
+	stageAttrTypes = map[string]attr.Type{
+		keys.ApiId:            types.StringType,
+		keys.StageName:        types.StringType,
+		keys.ApiType:          types.StringType,
+		keys.ExecutionLogging: types.StringType,
+		keys.AccessLogGroup:   types.StringType,
+		keys.MethodOverrides:  types.ListType{ElemType: types.ObjectType{AttrTypes: methodOverrideAttrTypes}},
+	}
+	failedAccountAttrTypes = map[string]attr.Type{
+		keys.Region:              types.StringType,
+		keys.AccountId:           types.StringType,
+		keys.CrossAccountRoleArn: types.StringType,
+		keys.Error:               types.StringType,
+	}
+	selectionAttrTypes = map[string]attr.Type{
+		keys.AccountId: types.StringType,
+		keys.Region:    types.StringType,
+		keys.ApiId:     types.StringType,
+		keys.ApiName:   types.StringType,
+		keys.StageName: types.StringType,
+		keys.Selected:  types.BoolType,
+		keys.Reason:    types.StringType,
+	}
+)
+
+type resourceModel struct {
+	Id         types.String `tfsdk:"id"`
+	Identifier types.String `tfsdk:"identifier"`
+	argumentsModel
+	LogGroupNames    types.List `tfsdk:"log_group_names"`
+	StageInventory   types.List `tfsdk:"stage_inventory"`
+	FailedAccounts   types.List `tfsdk:"failed_accounts"`
+	SelectionExplain types.List `tfsdk:"selection_explain"`
+}
+
+type dataSourceModel struct {
+	Id types.String `tfsdk:"id"`
+	argumentsModel
+	LogGroupNames    types.List `tfsdk:"log_group_names"`
+	StageInventory   types.List `tfsdk:"stage_inventory"`
+	FailedAccounts   types.List `tfsdk:"failed_accounts"`
+	SelectionExplain types.List `tfsdk:"selection_explain"`
+}
+
+type argumentsModel struct {
+	IgnoreAccessLogSettings types.Bool              `tfsdk:"ignore_access_log_settings"`
+	StrictLogGroupFormat    types.Bool              `tfsdk:"strict_log_group_format"`
+	Timeout                 types.String            `tfsdk:"timeout"`
+	FindingSeverity         map[string]types.String `tfsdk:"finding_severity"`
+	FailOn                  types.String            `tfsdk:"fail_on"`
+	FailOnUnmatched         types.Bool              `tfsdk:"fail_on_unmatched"`
+	ReportPath              types.String            `tfsdk:"report_path"`
+	ReportFormat            types.String            `tfsdk:"report_format"`
+	Accounts                []accountModel          `tfsdk:"accounts"`
+}
+
+type accountModel struct {
+	Region              types.String   `tfsdk:"region"`
+	IncludeApis         []types.String `tfsdk:"include_apis"`
+	ExcludeApis         []types.String `tfsdk:"exclude_apis"`
+	ApiList             []types.String `tfsdk:"api_list"`
+	CrossAccountRoleArn types.String   `tfsdk:"cross_account_role_arn"`
+	StsRegion           types.String   `tfsdk:"sts_region"`
+	Exclude             types.Bool     `tfsdk:"exclude"`
+}
+
+type stageModel struct {
+	ApiId            string                `tfsdk:"api_id"`
+	StageName        string                `tfsdk:"stage_name"`
+	ApiType          string                `tfsdk:"api_type"`
+	ExecutionLogging string                `tfsdk:"execution_logging"`
+	AccessLogGroup   string                `tfsdk:"access_log_group"`
+	MethodOverrides  []methodOverrideModel `tfsdk:"method_overrides"`
+}
+
+type failedAccountModel struct {
+	Region              string `tfsdk:"region"`
+	AccountId           string `tfsdk:"account_id"`
+	CrossAccountRoleArn string `tfsdk:"cross_account_role_arn"`
+	Error               string `tfsdk:"error"`
+}
+
+type selectionModel struct {
+	AccountId string `tfsdk:"account_id"`
+	Region    string `tfsdk:"region"`
+	ApiId     string `tfsdk:"api_id"`
+	ApiName   string `tfsdk:"api_name"`
+	StageName string `tfsdk:"stage_name"`
+	Selected  bool   `tfsdk:"selected"`
+	Reason    string `tfsdk:"reason"`
+}
+
+type methodOverrideModel struct {
+	Method           string `tfsdk:"method"`
+	LoggingLevel     string `tfsdk:"logging_level"`
+	DataTraceEnabled bool   `tfsdk:"data_trace_enabled"`
+}
+
+func (m *resourceModel) discoveryConfig() *discovery.Config {
+	discoveryConfig := m.argumentsModel.discoveryConfig()
+	discoveryConfig.Identifier = m.Identifier.ValueString()
+	return discoveryConfig
+}
+
+// hasDiscoveryResults is false for imported resources that were not read yet.
+func (m *resourceModel) hasDiscoveryResults() bool {
+	return isKnown(m.LogGroupNames) || isKnown(m.StageInventory)
+}
+
+func isKnown(value attr.Value) bool {
+	return !value.IsNull() && !value.IsUnknown()
+}
+
+func (m *resourceModel) setDiscoveryResults(ctx context.Context, result *discovery.Result) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	m.LogGroupNames, m.StageInventory, m.FailedAccounts, m.SelectionExplain, diagnostics = discoveryResults(ctx, result)
+	return diagnostics
+}
+
+func newResourceModel(imported *importConfig) *resourceModel {
+	accounts := make([]accountModel, 0, len(imported.Accounts))
+	for _, account := range imported.Accounts {
+		accountModel := accountModel{
+			Region:              types.StringValue(account.Region),
+			IncludeApis:         stringValues(account.IncludeApis),
+			ExcludeApis:         stringValues(account.ExcludeApis),
+			CrossAccountRoleArn: types.StringValue(account.CrossAccountRoleArn),
+			StsRegion:           types.StringNull(),
+			Exclude:             types.BoolPointerValue(account.Exclude),
+		}
+		// an empty api_list set by the import id stays set
+		if account.ApiList != nil {
+			accountModel.ApiList = append([]types.String{}, stringValues(account.ApiList)...)
+		}
+		if account.StsRegion != "" {
+			accountModel.StsRegion = types.StringValue(account.StsRegion)
+		}
+		accounts = append(accounts, accountModel)
+	}
+	discoveryConfig := &imported.Config
+	var findingSeverity map[string]types.String
+	if discoveryConfig.FindingSeverity != nil {
+		findingSeverity = make(map[string]types.String, len(discoveryConfig.FindingSeverity))
+		for code, severity := range discoveryConfig.FindingSeverity {
+			findingSeverity[code] = types.StringValue(severity)
+		}
+	}
+	// report_path has no default, an unset path stays null
+	reportPath := types.StringNull()
+	if discoveryConfig.ReportPath != "" {
+		reportPath = types.StringValue(discoveryConfig.ReportPath)
+	}
+	return &resourceModel{
+		Identifier: types.StringValue(discoveryConfig.Identifier),
+		argumentsModel: argumentsModel{
+			FindingSeverity:         findingSeverity,
+			FailOn:                  types.StringValue(discoveryConfig.FailOn),
+			FailOnUnmatched:         types.BoolValue(discoveryConfig.FailOnUnmatched),
+			ReportPath:              reportPath,
+			ReportFormat:            types.StringValue(discoveryConfig.ReportFormat),
+			IgnoreAccessLogSettings: types.BoolValue(discoveryConfig.IgnoreAccessLogSettings),
+			StrictLogGroupFormat:    types.BoolValue(discoveryConfig.StrictLogGroupFormat),
+			Timeout:                 types.StringValue(discoveryConfig.Timeout),
+			Accounts:                accounts,
+		},
+		LogGroupNames:    types.ListNull(types.StringType),
+		StageInventory:   types.ListNull(types.ObjectType{AttrTypes: stageAttrTypes}),
+		FailedAccounts:   types.ListNull(types.ObjectType{AttrTypes: failedAccountAttrTypes}),
+		SelectionExplain: types.ListNull(types.ObjectType{AttrTypes: selectionAttrTypes}),
+	}
+}
+
+func (m *dataSourceModel) setDiscoveryResults(ctx context.Context, result *discovery.Result) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	m.LogGroupNames, m.StageInventory, m.FailedAccounts, m.SelectionExplain, diagnostics = discoveryResults(ctx, result)
+	return diagnostics
+}
+
+func (m *argumentsModel) discoveryConfig() *discovery.Config {
+	discoveryConfig := &discovery.Config{
+		IgnoreAccessLogSettings: m.IgnoreAccessLogSettings.ValueBool(),
+		StrictLogGroupFormat:    m.StrictLogGroupFormat.ValueBool(),
+		Timeout:                 m.Timeout.ValueString(),
+		FailOn:                  m.FailOn.ValueString(),
+		FailOnUnmatched:         m.FailOnUnmatched.ValueBool(),
+		ReportPath:              m.ReportPath.ValueString(),
+		ReportFormat:            m.ReportFormat.ValueString(),
+	}
+	if m.FindingSeverity != nil {
+		discoveryConfig.FindingSeverity = make(map[string]string, len(m.FindingSeverity))
+		for code, severity := range m.FindingSeverity {
+			discoveryConfig.FindingSeverity[code] = severity.ValueString()
+		}
+	}
+	for _, account := range m.Accounts {
+		discoveryConfig.Accounts = append(discoveryConfig.Accounts, discovery.AccountConfig{
+			Region:              account.Region.ValueString(),
+			IncludeApis:         stringsOf(account.IncludeApis),
+			ExcludeApis:         stringsOf(account.ExcludeApis),
+			ApiList:             append([]string{}, stringsOf(account.ApiList)...),
+			CrossAccountRoleArn: account.CrossAccountRoleArn.ValueString(),
+			StsRegion:           account.StsRegion.ValueString(),
+			Exclude:             account.Exclude.ValueBool(),
+		})
+	}
+	return discoveryConfig
+}
+
+// stringValues returns nil for an empty list so that it stays unset.
+func stringValues(values []string) []types.String {
+	if len(values) == 0 {
+		return nil
+	}
+	list := make([]types.String, 0, len(values))
+	for _, value := range values {
+		list = append(list, types.StringValue(value))
+	}
+	return list
+}
+
+func stringsOf(list []types.String) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(list))
+	for _, value := range list {
+		values = append(values, value.ValueString())
+	}
+	return values
+}
+
+func discoveryResults(ctx context.Context, result *discovery.Result) (types.List, types.List, types.List, types.List, diag.Diagnostics) {
+	var diagnostics diag.Diagnostics
+	logGroupNamesValue, d := types.ListValueFrom(ctx, types.StringType, result.LogGroupNames)
+	diagnostics.Append(d...)
+	stages := make([]stageModel, 0, len(result.Stages))
+	for _, stage := range result.Stages {
+		methodOverrides := make([]methodOverrideModel, 0, len(stage.MethodOverrides))
+		for _, override := range stage.MethodOverrides {
+			methodOverrides = append(methodOverrides, methodOverrideModel{
+				Method:           override.Method,
+				LoggingLevel:     override.LoggingLevel,
+				DataTraceEnabled: override.DataTraceEnabled,
+			})
+		}
+		stages = append(stages, stageModel{
+			ApiId:            stage.ApiId,
+			StageName:        stage.StageName,
+			ApiType:          stage.ApiType,
+			ExecutionLogging: stage.ExecutionLogging,
+			AccessLogGroup:   stage.AccessLogGroup,
+			MethodOverrides:  methodOverrides,
+		})
+	}
+	stageInventoryValue, d := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: stageAttrTypes}, stages)
+	diagnostics.Append(d...)
+	failedAccountModels := make([]failedAccountModel, 0, len(result.FailedAccounts))
+	for _, failedAccount := range result.FailedAccounts {
+		failedAccountModels = append(failedAccountModels, failedAccountModel{
+			Region:              failedAccount.Region,
+			AccountId:           failedAccount.AccountId,
+			CrossAccountRoleArn: failedAccount.CrossAccountRoleArn,
+			Error:               failedAccount.Error,
+		})
+	}
+	failedAccountsValue, d := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: failedAccountAttrTypes}, failedAccountModels)
+	diagnostics.Append(d...)
+	selections := make([]selectionModel, 0, len(result.Selections))
+	for _, selection := range result.Selections {
+		selections = append(selections, selectionModel{
+			AccountId: selection.AccountId,
+			Region:    selection.Region,
+			ApiId:     selection.ApiId,
+			ApiName:   selection.ApiName,
+			StageName: selection.StageName,
+			Selected:  selection.Selected,
+			Reason:    selection.Reason,
+		})
+	}
+	selectionExplainValue, d := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: selectionAttrTypes}, selections)
+	diagnostics.Append(d...)
+	return logGroupNamesValue, stageInventoryValue, failedAccountsValue, selectionExplainValue, diagnostics
+}
@@ -2,71 +2,225 @@ package provider
 
 import (
 	"context"
+	"time"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
 	"github.com/Traceableai/terraform-provider-awsapigateway/provider/keys"
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
-	"github.com/aws/aws-sdk-go-v2/service/sts"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ provider.Provider              = (*terraformProvider)(nil)
+	_ provider.ProviderWithFunctions = (*terraformProvider)(nil)
 )
 
-func Provider() *schema.Provider {
-	return createProvider(providerConfigure)
+type terraformProvider struct {
+	version string
+}
+
+type providerModel struct {
+	Profile                        types.String      `tfsdk:"profile"`
+	Region                         types.String      `tfsdk:"region"`
+	Partition                      types.String      `tfsdk:"partition"`
+	StsRegion                      types.String      `tfsdk:"sts_region"`
+	CacheTtl                       types.String      `tfsdk:"cache_ttl"`
+	AccountsUseProviderCredentials types.Bool        `tfsdk:"accounts_use_provider_credentials"`
+	AssumeRole                     []assumeRoleModel `tfsdk:"assume_role"`
+	Endpoints                      []endpointsModel  `tfsdk:"endpoints"`
+}
+
+type assumeRoleModel struct {
+	RoleArn types.String `tfsdk:"role_arn"`
 }
 
-func createProvider(configureContextFunc schema.ConfigureContextFunc) *schema.Provider {
-	return &schema.Provider{
-		Schema: map[string]*schema.Schema{
-			keys.Profile: {
-				Type:     schema.TypeString,
+type endpointsModel struct {
+	ApiGateway   types.String `tfsdk:"apigateway"`
+	ApiGatewayV2 types.String `tfsdk:"apigatewayv2"`
+	Sts          types.String `tfsdk:"sts"`
+}
+
+type providerData struct {
+	// clientFactory reads AWS through the cache of the provider, if enabled
+	clientFactory discovery.ClientFactory
+	// partition overrides the partition of the accounts, empty to use the one of their region
+	partition discovery.Partition
+	// stsRegion is the STS region of the accounts without one, empty for their region
+	stsRegion string
+}
+
+// clientFactoryOf returns the default client factory when the provider was not configured.
+func clientFactoryOf(data *providerData) discovery.ClientFactory {
+	if data == nil || data.clientFactory == nil {
+		return discovery.DefaultClientFactory
+	}
+	return data.clientFactory
+}
+
+func New(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &terraformProvider{version: version}
+	}
+}
+
+func (p *terraformProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "awsapigateway"
+	resp.Version = p.version
+}
+
+func (p *terraformProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			keys.Profile: schema.StringAttribute{
 				Optional: true,
-				Default:  "",
 			},
-			keys.Region: {
-				Type:     schema.TypeString,
+			keys.Region: schema.StringAttribute{
+				Optional:   true,
+				Validators: []validator.String{regionValidator(true)},
+			},
+			keys.Partition: schema.StringAttribute{
+				Optional: true,
+				Description: "Partition of the accounts, `aws`, `aws-cn` or `aws-us-gov`. Defaults to the partition of the " +
+					"region of each account, it sets the partition expected in role ARNs and the console of the links in findings.",
+				Validators: []validator.String{stringvalidator.OneOf(discovery.Partitions...)},
+			},
+			keys.StsRegion: schema.StringAttribute{
 				Optional: true,
-				Default:  "",
+				Description: "Region of the STS endpoint assuming the roles, `assume_role` and the `cross_account_role_arn` " +
+					"of the accounts without `sts_region`. Defaults to the region of the provider and of each account.",
+				Validators: []validator.String{regionValidator(false)},
 			},
-			keys.AssumeRole: {
-				Type:     schema.TypeList,
+			keys.CacheTtl: schema.StringAttribute{
 				Optional: true,
-				MaxItems: 1,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						keys.RoleArn: {
-							Type:     schema.TypeString,
-							Required: true,
+				Description: "How long the API Gateway and STS responses are shared by the resources and data sources reading " +
+					"the same accounts, up to `1h`. Defaults to `5m`, `0s` disables the cache. The credentials of the roles are " +
+					"shared until they expire whatever the TTL.",
+				Validators: []validator.String{cacheTtlValidator()},
+			},
+			keys.AccountsUseProviderCredentials: schema.BoolAttribute{
+				Optional: true,
+				Description: "Read the accounts with the `profile` and the `assume_role` of the provider, the role of " +
+					"`assume_role` then assuming the `cross_account_role_arn` of the accounts. Defaults to `false`, the " +
+					"accounts being read with the default credential chain.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			keys.AssumeRole: schema.ListNestedBlock{
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						keys.RoleArn: schema.StringAttribute{
+							Required:   true,
+							Validators: []validator.String{roleArnValidator(false)},
+						},
+					},
+				},
+			},
+			keys.Endpoints: schema.ListNestedBlock{
+				Description: "Custom endpoints of the AWS services, for instance to run against a fake of AWS in tests.",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						keys.ApiGateway: schema.StringAttribute{
+							Optional:    true,
+							Description: "Endpoint of API Gateway, used for REST APIs.",
+						},
+						keys.ApiGatewayV2: schema.StringAttribute{
+							Optional:    true,
+							Description: "Endpoint of API Gateway V2, used for HTTP and WebSocket APIs.",
+						},
+						keys.Sts: schema.StringAttribute{
+							Optional:    true,
+							Description: "Endpoint of STS, used to assume the cross account roles.",
 						},
 					},
 				},
 			},
 		},
-		ResourcesMap: map[string]*schema.Resource{
-			keys.AwsApiGatewayResource: AwsApiGatewayResource(),
-		},
-
-		ConfigureContextFunc: configureContextFunc,
 	}
 }
 
-func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithSharedConfigProfile(d.Get("profile").(string)),
-		config.WithRegion(d.Get("region").(string)),
-	)
+func (p *terraformProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var data providerModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
+	var endpoints discovery.Endpoints
+	if len(data.Endpoints) > 0 {
+		endpoints = discovery.Endpoints{
+			ApiGateway:   data.Endpoints[0].ApiGateway.ValueString(),
+			ApiGatewayV2: data.Endpoints[0].ApiGatewayV2.ValueString(),
+			Sts:          data.Endpoints[0].Sts.ValueString(),
+		}
+	}
+
+	var loadOptions []func(*config.LoadOptions) error
+	if profile := data.Profile.ValueString(); profile != "" {
+		loadOptions = append(loadOptions, config.WithSharedConfigProfile(profile))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, append([]func(*config.LoadOptions) error{
+		config.WithRegion(data.Region.ValueString()),
+	}, loadOptions...)...)
 	if err != nil {
-		return nil, diag.FromErr(err)
+		resp.Diagnostics.AddError("Unable to load AWS configuration", err.Error())
+		return
+	}
+	// the accounts use the default credential chain unless they opt in to the provider credentials
+	if !data.AccountsUseProviderCredentials.ValueBool() {
+		loadOptions = nil
+	} else if len(data.AssumeRole) > 0 {
+		credentials := discovery.AssumeRole(cfg, endpoints, data.AssumeRole[0].RoleArn.ValueString(), data.StsRegion.ValueString())
+		loadOptions = append(loadOptions, config.WithCredentialsProvider(credentials))
+	}
+	clientFactory := discovery.NewClientFactory(endpoints, loadOptions...)
+	cacheTtl := discovery.DefaultCacheTtl
+	if !data.CacheTtl.IsNull() {
+		// validated by the schema
+		cacheTtl, _ = time.ParseDuration(data.CacheTtl.ValueString())
+	}
+	if cacheTtl > 0 {
+		clientFactory = discovery.NewCache(cacheTtl).ClientFactory(clientFactory)
+	}
+	providerData := &providerData{
+		clientFactory: clientFactory,
+		partition:     discovery.Partition(data.Partition.ValueString()),
+		stsRegion:     data.StsRegion.ValueString(),
 	}
+	resp.ResourceData = providerData
+	resp.DataSourceData = providerData
+}
+
+func (p *terraformProvider) Resources(_ context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		NewAwsApiGatewayResource,
+	}
+}
 
-	if assumeRoleRaw, ok := d.GetOk("assume_role"); ok {
-		assumeRole := assumeRoleRaw.([]interface{})[0]
-		role := assumeRole.(map[string]interface{})["role_arn"].(string)
-		stsSvc := sts.NewFromConfig(cfg)
-		creds := stscreds.NewAssumeRoleProvider(stsSvc, role)
-		cfg.Credentials = aws.NewCredentialsCache(creds)
+func (p *terraformProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{
+		NewAwsApiGatewayLogGroupsDataSource,
 	}
+}
 
-	return newFromConfig(cfg), nil
+func (p *terraformProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewExecutionLogGroupNameFunction,
+		NewAccessLogGroupFromArnFunction,
+		NewParseAccessLogFormatFunction,
+		NewValidateAccessLogFormatFunction,
+	}
 }
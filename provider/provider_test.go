@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/internal/fakeaws"
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	"github.com/Traceableai/terraform-provider-awsapigateway/provider/keys"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvider(t *testing.T) {
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	assert.NoError(t, err)
+
+	resp, err := server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Diagnostics)
+	assert.Contains(t, resp.ResourceSchemas, "awsapigateway_resource")
+	assert.Contains(t, resp.DataSourceSchemas, "awsapigateway_log_groups")
+	assert.Len(t, resp.Functions, 4)
+}
+
+// configure runs Configure with the given attributes, the others being null, and returns
+// the data handed to the resources.
+func configure(t *testing.T, attributes map[string]tftypes.Value) (*providerData, diag.Diagnostics) {
+	ctx := context.Background()
+	p := New("test")()
+	var schemaResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attributeType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attributeType, nil)
+		if value, found := attributes[name]; found {
+			values[name] = value
+		}
+	}
+
+	var resp provider.ConfigureResponse
+	p.Configure(ctx, provider.ConfigureRequest{Config: tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(objectType, values),
+	}}, &resp)
+	data, _ := resp.ResourceData.(*providerData)
+	return data, resp.Diagnostics
+}
+
+func TestConfigure(t *testing.T) {
+	server := fakeaws.NewServer(t, "testdata/fakeaws.yaml")
+	server.SetCredentials(t)
+	endpointsType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		keys.ApiGateway: tftypes.String, keys.ApiGatewayV2: tftypes.String, keys.Sts: tftypes.String,
+	}}
+	endpoints := tftypes.NewValue(tftypes.List{ElementType: endpointsType}, []tftypes.Value{
+		tftypes.NewValue(endpointsType, map[string]tftypes.Value{
+			keys.ApiGateway:   tftypes.NewValue(tftypes.String, server.URL),
+			keys.ApiGatewayV2: tftypes.NewValue(tftypes.String, server.URL),
+			keys.Sts:          tftypes.NewValue(tftypes.String, server.URL),
+		}),
+	})
+	assumeRoleType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{keys.RoleArn: tftypes.String}}
+	assumeRole := tftypes.NewValue(tftypes.List{ElementType: assumeRoleType}, []tftypes.Value{
+		tftypes.NewValue(assumeRoleType, map[string]tftypes.Value{
+			keys.RoleArn: tftypes.NewValue(tftypes.String, "arn:aws:iam::210987654321:role/provider"),
+		}),
+	})
+	accountId := func(data *providerData) string {
+		clients, err := clientFactoryOf(data)(context.Background(), discovery.AccountSpec{Region: "us-east-1"})
+		assert.NoError(t, err)
+		identity, err := clients.Sts.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+		assert.NoError(t, err)
+		return aws.ToString(identity.Account)
+	}
+
+	data, diagnostics := configure(t, map[string]tftypes.Value{keys.Endpoints: endpoints})
+	assert.Empty(t, diagnostics)
+	assert.Equal(t, "123456789012", accountId(data))
+
+	// the role of the provider is not assumed by the accounts, their trust policies name the
+	// principal of the default credential chain
+	data, diagnostics = configure(t, map[string]tftypes.Value{
+		keys.Endpoints:  endpoints,
+		keys.AssumeRole: assumeRole,
+		keys.Region:     tftypes.NewValue(tftypes.String, "us-east-1"),
+	})
+	assert.Empty(t, diagnostics)
+	assert.Equal(t, "123456789012", accountId(data))
+
+	// unless they opt in to the credentials of the provider
+	data, diagnostics = configure(t, map[string]tftypes.Value{
+		keys.Endpoints:                      endpoints,
+		keys.AssumeRole:                     assumeRole,
+		keys.Region:                         tftypes.NewValue(tftypes.String, "us-east-1"),
+		keys.AccountsUseProviderCredentials: tftypes.NewValue(tftypes.Bool, true),
+	})
+	assert.Empty(t, diagnostics)
+	assert.Equal(t, "210987654321", accountId(data))
+
+	// the profile of the provider is loaded whether the accounts use it or not
+	_, diagnostics = configure(t, map[string]tftypes.Value{
+		keys.Endpoints: endpoints,
+		keys.Profile:   tftypes.NewValue(tftypes.String, "missing"),
+	})
+	assert.True(t, diagnostics.HasError())
+	assert.Contains(t, diagnostics.Errors()[0].Detail(), "missing")
+}
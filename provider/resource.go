@@ -2,438 +2,382 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"regexp"
-	"strings"
-	"time"
 
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
 	"github.com/Traceableai/terraform-provider-awsapigateway/provider/keys"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
-	v1 "github.com/aws/aws-sdk-go-v2/service/apigateway"
-	v2 "github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
-	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-func AwsApiGatewayResource() *schema.Resource {
-	return &schema.Resource{
-		CreateContext: resourceCreateUpdate,
-		ReadContext:   resourceRead,
-		UpdateContext: resourceCreateUpdate,
-		DeleteContext: resourceDelete,
+// states of version 0 were written by terraform-plugin-sdk/v2.
+const resourceSchemaVersion = 1
 
-		Schema: map[string]*schema.Schema{
-			keys.Identifier: {
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "",
-			},
-			keys.IgnoreAccessLogSettings: {
-				Type:     schema.TypeBool,
-				Optional: true,
-				Default:  false,
-			},
-			keys.LogGroupNames: {
-				Type:     schema.TypeList,
-				Computed: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
-			},
-			keys.Timeout: {
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "1m",
-			},
-			keys.Accounts: {
-				Type:     schema.TypeList,
-				Required: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						keys.Region: {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						keys.ApiList: {
-							Type:     schema.TypeList,
-							Required: true,
-							Elem:     &schema.Schema{Type: schema.TypeString},
-						},
-						keys.CrossAccountRoleArn: {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						keys.Exclude: {
-							Type:     schema.TypeBool,
-							Required: true,
-						},
-					},
-				},
-			},
-		},
-	}
+var (
+	_ resource.Resource                 = (*awsApiGatewayResource)(nil)
+	_ resource.ResourceWithModifyPlan   = (*awsApiGatewayResource)(nil)
+	_ resource.ResourceWithImportState  = (*awsApiGatewayResource)(nil)
+	_ resource.ResourceWithUpgradeState = (*awsApiGatewayResource)(nil)
+	_ resource.ResourceWithConfigure    = (*awsApiGatewayResource)(nil)
+)
+
+type awsApiGatewayResource struct {
+	providerData *providerData
 }
 
-func resourceCreateUpdate(
-	ctx context.Context,
-	d *schema.ResourceData,
-	_ interface{}) diag.Diagnostics {
-	mapDiagnostics := &MapDiagnostics{
-		diagnostics:      diag.Diagnostics{},
-		warnDiagnostics:  make(map[string][]string),
-		errorDiagnostics: make(map[string][]string),
-	}
+func NewAwsApiGatewayResource() resource.Resource {
+	return &awsApiGatewayResource{}
+}
 
-	logGroupNames := make([]string, 0)
-	accounts := d.Get(keys.Accounts).([]interface{})
-	timeoutStr := d.Get(keys.Timeout).(string)
-	timeout, err := time.ParseDuration(timeoutStr)
-	if err != nil {
-		mapDiagnostics.add(errorDiagnostic(err.Error()))
-		return mapDiagnostics.getDiagnostics()
-	}
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+func (r *awsApiGatewayResource) Metadata(_ context.Context, _ resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = keys.AwsApiGatewayResource
+}
 
-	tflog.Info(ctx, "Initializing provider")
-	for _, account := range accounts {
-		acc := account.(map[string]interface{})
-		tflog.Debug(ctx, "fetching details of account", acc)
+func (r *awsApiGatewayResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("expected *providerData, got %T", req.ProviderData))
+		return
+	}
+	r.providerData = data
+}
 
-		region := acc[keys.Region].(string)
-		apiList := acc[keys.ApiList].([]interface{})
-		crossAccRoleArn := acc[keys.CrossAccountRoleArn].(string)
-		exclude := acc[keys.Exclude].(bool)
+func (r *awsApiGatewayResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = resourceSchema()
+}
 
-		ignoreAccessLogSettings := d.Get(keys.IgnoreAccessLogSettings).(bool)
+func resourceSchema() schema.Schema {
+	attributes := map[string]schema.Attribute{
+		keys.Id: schema.StringAttribute{
+			Computed: true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		},
+		keys.Identifier: schema.StringAttribute{
+			Optional: true,
+			Computed: true,
+			Default:  stringdefault.StaticString(""),
+		},
+		keys.IgnoreAccessLogSettings: schema.BoolAttribute{
+			Optional: true,
+			Computed: true,
+			Default:  booldefault.StaticBool(false),
+		},
+		keys.StrictLogGroupFormat: schema.BoolAttribute{
+			Optional: true,
+			Computed: true,
+			Default:  booldefault.StaticBool(false),
+		},
+		keys.Timeout: schema.StringAttribute{
+			Optional:   true,
+			Computed:   true,
+			Default:    stringdefault.StaticString("1m"),
+			Validators: []validator.String{timeoutValidator()},
+		},
+		keys.FindingSeverity: schema.MapAttribute{
+			Optional:    true,
+			ElementType: types.StringType,
+			Validators:  findingSeverityValidators(),
+		},
+		keys.FailOn: schema.StringAttribute{
+			Optional:   true,
+			Computed:   true,
+			Default:    stringdefault.StaticString(string(discovery.FailOnAnyError)),
+			Validators: []validator.String{stringvalidator.OneOf(discovery.FailOnModes...)},
+		},
+		keys.FailOnUnmatched: schema.BoolAttribute{
+			Optional:    true,
+			Computed:    true,
+			Default:     booldefault.StaticBool(false),
+			Description: failOnUnmatchedDescription,
+		},
+		keys.ReportPath: schema.StringAttribute{
+			Optional:    true,
+			Description: "File the report of the discovery is written to when the resource is created or updated, plans do not write it.",
+			Validators:  []validator.String{stringvalidator.LengthAtLeast(1)},
+		},
+		keys.ReportFormat: schema.StringAttribute{
+			Optional:   true,
+			Computed:   true,
+			Default:    stringdefault.StaticString(string(discovery.ReportFormatJson)),
+			Validators: []validator.String{stringvalidator.OneOf(discovery.ReportFormats...)},
+		},
+	}
+	resourceResultAttributes(attributes)
+	return schema.Schema{
+		Version:    resourceSchemaVersion,
+		Attributes: attributes,
+		Blocks: map[string]schema.Block{
+			keys.Accounts: resourceAccountsBlock(),
+		},
+	}
+}
 
-		cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
-		if err != nil {
-			tflog.Error(ctx, fmt.Sprintf("Error loading AWS configuration: %v", err))
-			mapDiagnostics.add(errorDiagnostic(err.Error()))
-			continue
-		}
+const (
+	stsRegionDescription = "Region of the STS endpoint assuming `cross_account_role_arn` and reading the account ID, " +
+		"for instance when STS is not enabled in the region of the account. Defaults to the `sts_region` of the provider, " +
+		"then to `region`. Accounts sharing a role and an STS region share its credentials."
+	includeApisDescription = "APIs and `api/stage` entries whose stages are checked. The API part matches the ID or the " +
+		"name of an API, and both parts may use the `*` and `?` wildcards. The most specific entry of `include_apis` and " +
+		"`exclude_apis` matching a stage decides, an exclusion winning over an inclusion as specific."
+	excludeApisDescription = "APIs and `api/stage` entries whose stages are left out, with the syntax of `include_apis`. " +
+		"Without `include_apis`, every other stage is checked."
+	apiListDeprecation = "Use include_apis instead, or exclude_apis for the entries of an api_list with exclude = true. " +
+		"api_list will be removed in the next major version."
+	failOnUnmatchedDescription = "Report the entries of `include_apis`, `exclude_apis` and `api_list` that match no API " +
+		"or stage as errors instead of warnings. The `selector_unmatched` code of `finding_severity` overrides it."
+	selectionExplainDescription = "Why each API and stage of the accounts was checked or left out. `stage_name` is " +
+		"empty for APIs left out as a whole."
+	excludeDeprecation = "Move the entries of api_list to exclude_apis instead of setting exclude = true. " +
+		"exclude will be removed in the next major version."
+)
 
-		// if cross account role arn is provided, then reinitialise client with an assumed role
-		if len(crossAccRoleArn) > 0 {
-			tflog.Info(ctx, "cross account role arn found, using that to initialize client")
-			stsSvc := sts.NewFromConfig(cfg)
-			creds := stscreds.NewAssumeRoleProvider(stsSvc, crossAccRoleArn)
-			cfg.Credentials = aws.NewCredentialsCache(creds)
-		}
+func apiListValidators() []validator.List {
+	return []validator.List{
+		listvalidator.ConflictsWith(
+			path.MatchRelative().AtParent().AtName(keys.IncludeApis),
+			path.MatchRelative().AtParent().AtName(keys.ExcludeApis),
+		),
+		selectorEntriesValidator(false),
+	}
+}
 
-		conn := newFromConfig(cfg)
+func findingSeverityValidators() []validator.Map {
+	return []validator.Map{
+		mapvalidator.KeysAre(stringvalidator.OneOf(discovery.FindingCodes()...)),
+		mapvalidator.ValueStringsAre(stringvalidator.OneOf(discovery.FindingSeverities...)),
+	}
+}
 
-		logGroupNames = append(logGroupNames,
-			getLogGroupNames(ctx, apiList, exclude, ignoreAccessLogSettings, conn, mapDiagnostics)...)
+// ModifyPlan runs discovery so that the plan shows the log group names and every finding.
+func (r *awsApiGatewayResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	if !req.Config.Raw.IsFullyKnown() {
+		tflog.Info(ctx, "configuration is not known yet, skipping discovery at plan time")
+		return
 	}
 
-	if d.Id() == "" {
-		d.SetId(uuid.New().String())
+	var plan resourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if err := d.Set(keys.LogGroupNames, logGroupNames); err != nil {
-		mapDiagnostics.add(errorDiagnostic(err.Error()))
+	result, diagnostics := discover(ctx, plan.discoveryConfig(), r.providerData)
+	resp.Diagnostics.Append(diagnostics...)
+	if result == nil {
+		return
 	}
+	resp.Diagnostics.Append(plan.setDiscoveryResults(ctx, result)...)
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
 
-	return mapDiagnostics.getDiagnostics()
+func (r *awsApiGatewayResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan resourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !r.applyDiscoveryResults(ctx, &plan, &resp.Diagnostics) {
+		return
+	}
+	plan.Id = types.StringValue(uuid.New().String())
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func resourceRead(_ context.Context, _ *schema.ResourceData, _ interface{}) diag.Diagnostics {
-	return nil
+func (r *awsApiGatewayResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan resourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !r.applyDiscoveryResults(ctx, &plan, &resp.Diagnostics) {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func resourceDelete(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
-	d.SetId("")
-	return nil
+// applyDiscoveryResults keeps the results of the plan and returns false if the stages changed
+// since, discovery runs again when they were unknown or to write the report.
+func (r *awsApiGatewayResource) applyDiscoveryResults(ctx context.Context, plan *resourceModel, diagnostics *diag.Diagnostics) bool {
+	planned := !plan.LogGroupNames.IsUnknown() && !plan.StageInventory.IsUnknown() && !plan.FailedAccounts.IsUnknown() && !plan.SelectionExplain.IsUnknown()
+	discoveryConfig := plan.discoveryConfig()
+	if planned && discoveryConfig.ReportPath == "" {
+		return true
+	}
+	result, discoveryDiagnostics := discover(ctx, discoveryConfig, r.providerData)
+	if result == nil {
+		diagnostics.Append(discoveryDiagnostics...)
+		return false
+	}
+	if planned {
+		// the findings were reported by the plan, whose results are kept. The report must
+		// describe them, so the stages must not have changed since.
+		applied := *plan
+		diagnostics.Append(applied.setDiscoveryResults(ctx, result)...)
+		if !applied.LogGroupNames.Equal(plan.LogGroupNames) || !applied.StageInventory.Equal(plan.StageInventory) ||
+			!applied.FailedAccounts.Equal(plan.FailedAccounts) || !applied.SelectionExplain.Equal(plan.SelectionExplain) {
+			diagnostics.AddError("Discovery results changed after the plan",
+				"The stages read to write "+discoveryConfig.ReportPath+" differ from those of the plan, plan and apply again.")
+			return false
+		}
+		diagnostics.Append(writeReport(result, discoveryConfig)...)
+		return true
+	}
+	diagnostics.Append(discoveryDiagnostics...)
+	diagnostics.Append(writeReport(result, discoveryConfig)...)
+	diagnostics.Append(plan.setDiscoveryResults(ctx, result)...)
+	return true
 }
 
-func getLogGroupNames(
-	ctx context.Context,
-	apiGateways []interface{},
-	exclude bool,
-	ignoreAccessLogSettings bool,
-	conn AwsApiGatewayProvider,
-	mapDiagnostics *MapDiagnostics) []string {
-	var summary string
-	if !exclude && len(apiGateways) == 0 {
-		summary = "api_gateways cannot be empty when action is include."
-		mapDiagnostics.add(errorDiagnostic(summary))
-		return []string{}
+func (r *awsApiGatewayResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state resourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() || state.hasDiscoveryResults() {
+		return
 	}
 
-	// apiAllStages stores api ids where all stages need to be considered
-	// apiWithStage is a map of api id to list of api stages that need to be considered
-	// any api id can only belong to either apiAllStages slice or apiWithStage map
-	var apiAllStages []string
-	apiWithStage := make(map[string][]string)
-	for _, elem := range apiGateways {
-		value := elem.(string)
-		apiDetails := strings.Split(value, "/")
-		if len(apiDetails) == 2 {
-			if !contains(apiAllStages, apiDetails[0]) {
-				apiWithStage[apiDetails[0]] = append(apiWithStage[apiDetails[0]], apiDetails[1])
-			}
-		} else if len(apiDetails) == 1 {
-			apiAllStages = append(apiAllStages, apiDetails[0])
-			if _, ok := apiWithStage[apiDetails[0]]; ok {
-				delete(apiWithStage, apiDetails[0])
-			}
+	// an imported resource has no discovery results yet, they are rebuilt from its accounts
+	tflog.Info(ctx, "no discovery results in state, rebuilding log group names")
+	result, diagnostics := discover(ctx, state.discoveryConfig(), r.providerData)
+	// findings must not fail a refresh or an import, they are reported as errors on the next plan
+	for _, diagnostic := range diagnostics {
+		if withPath, ok := diagnostic.(diag.DiagnosticWithPath); ok {
+			resp.Diagnostics.AddAttributeWarning(withPath.Path(), diagnostic.Summary(), diagnostic.Detail())
 		} else {
-			mapDiagnostics.addError(WrongSyntax.new(), value)
+			resp.Diagnostics.AddWarning(diagnostic.Summary(), diagnostic.Detail())
 		}
 	}
-
-	accessLogFormatKeysMap := make(map[string]AccessLogFormatMap)
-	logGroupNames := getLogGroupNamesRestApis(
-		ctx,
-		conn,
-		apiAllStages,
-		apiWithStage,
-		exclude,
-		ignoreAccessLogSettings,
-		accessLogFormatKeysMap,
-		mapDiagnostics)
-
-	apiGatewayV2LogGroupNames := getLogGroupNamesHttpApis(
-		ctx,
-		conn,
-		apiAllStages,
-		apiWithStage,
-		exclude,
-		ignoreAccessLogSettings,
-		accessLogFormatKeysMap,
-		mapDiagnostics)
-	return removeDuplicates(append(logGroupNames, apiGatewayV2LogGroupNames...))
+	if result == nil {
+		return
+	}
+	resp.Diagnostics.Append(state.setDiscoveryResults(ctx, result)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
-func getLogGroupNamesRestApis(
-	ctx context.Context,
-	conn AwsApiGatewayProvider,
-	apiAllStages []string,
-	apiWithStage map[string][]string,
-	exclude bool,
-	ignoreAccessLogSettings bool,
-	accessLogFormatKeysMap map[string]AccessLogFormatMap,
-	mapDiagnostics *MapDiagnostics) []string {
-	// apiStageMappingRest is a map of api id to list of api stages that need to be considered
-	// if the value list is empty, it means that all stages in this api should be considered
-	apiStageMappingRest := make(map[string][]string)
-	restApisPaginator := conn.getAwsGetRestApisPaginator()
-	for restApisPaginator.HasMorePages() {
-		res, err := restApisPaginator.NextPage(ctx)
-		if err != nil {
-			summary := fmt.Sprintf("Error while invoking getRestApis sdk call: %s", err.Error())
-			mapDiagnostics.add(errorDiagnostic(summary))
-			// restApisPaginator.HasMorePages() will return true even if there are connection issues
-			return []string{}
-		}
-		for _, restApi := range res.Items {
-			apiId := *restApi.Id
-			apiStages, partial := apiWithStage[apiId]
-			if partial {
-				apiStageMappingRest[apiId] = apiStages
-			} else if contains(apiAllStages, apiId) != exclude {
-				apiStageMappingRest[apiId] = []string{}
-			}
-		}
-	}
-	logGroupNames := getLogGroupNamesRestApisHelper(
-		ctx,
-		conn,
-		apiStageMappingRest,
-		exclude,
-		ignoreAccessLogSettings,
-		accessLogFormatKeysMap,
-		mapDiagnostics)
-	return logGroupNames
+func (r *awsApiGatewayResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
 }
 
-func getLogGroupNamesHttpApis(
-	ctx context.Context,
-	conn AwsApiGatewayProvider,
-	apiAllStages []string,
-	apiWithStage map[string][]string,
-	exclude bool,
-	ignoreAccessLogSettings bool,
-	accessLogFormatKeysMap map[string]AccessLogFormatMap,
-	mapDiagnostics *MapDiagnostics) []string {
-	var summary string
-	// apiStageMappingRest is a map of api id to list of api stages that need to be considered
-	// if the value list is empty, it means that all stages in this api should be considered
-	apiStageMappingV2 := make(map[string][]string)
-	apiGatewayV2Client := conn.getApiGatewayV2Client()
-	res, err := apiGatewayV2Client.GetApis(ctx, &v2.GetApisInput{})
+func (r *awsApiGatewayResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	imported, err := parseImportId(req.ID)
 	if err != nil {
-		summary = fmt.Sprintf("Error while invoking getApis sdk call: %s", err.Error())
-		mapDiagnostics.add(errorDiagnostic(summary))
-		return []string{}
-	}
-	for _, httpApi := range res.Items {
-		apiId := *httpApi.ApiId
-		apiStages, partial := apiWithStage[apiId]
-		if partial {
-			apiStageMappingV2[apiId] = apiStages
-		} else if contains(apiAllStages, apiId) != exclude {
-			apiStageMappingV2[apiId] = []string{}
-		}
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
 	}
-	if !ignoreAccessLogSettings {
-		return getLogGroupNamesHttpApisHelper(
-			ctx,
-			conn,
-			apiStageMappingV2,
-			exclude,
-			accessLogFormatKeysMap,
-			mapDiagnostics)
-	}
-	return []string{}
+	state := newResourceModel(imported)
+	state.Id = types.StringValue(uuid.New().String())
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
 }
 
-func getLogGroupNamesRestApisHelper(
-	ctx context.Context,
-	conn AwsApiGatewayProvider,
-	apiStageMappingRest map[string][]string,
-	exclude bool,
-	ignoreAccessLogSettings bool,
-	accessLogFormatKeysMap map[string]AccessLogFormatMap,
-	mapDiagnostics *MapDiagnostics) []string {
-
-	var logGroupNames []string
-	apiGatewayClient := conn.getApiGatewayClient()
-	for apiId, apiStages := range apiStageMappingRest {
-		res, err := apiGatewayClient.GetStages(ctx, &v1.GetStagesInput{
-			RestApiId: &apiId,
-		})
-		if err != nil {
-			summary := fmt.Sprintf("Error while invoking getStages sdk call: %s", err.Error())
-			mapDiagnostics.add(errorDiagnostic(summary))
-			continue
-		}
-		for _, stage := range res.Item {
-			stageName := *(stage.StageName)
-			apiIdWithStageName := strings.Join([]string{apiId, stageName}, "/")
-			if len(apiStages) > 0 && contains(apiStages, stageName) == exclude {
-				continue
-			}
-			if settings, ok := stage.MethodSettings["*/*"]; ok {
-				if *(settings.LoggingLevel) == "INFO" && settings.DataTraceEnabled {
-					logGroupNames = append(logGroupNames, getExecutionLogGroupName(apiId, stageName))
-				} else if *(settings.LoggingLevel) == "INFO" {
-					mapDiagnostics.addError(FullRequestAndResponseLogNotEnabled.new(), apiIdWithStageName)
-				} else if *(settings.LoggingLevel) == "ERROR" {
-					mapDiagnostics.addError(ExecutionLogErrorOnly.new(), apiIdWithStageName)
-				} else {
-					mapDiagnostics.addError(ExecutionLogNotEnabled.new(), apiIdWithStageName)
+// UpgradeState upgrades the states of the terraform-plugin-sdk/v2 releases.
+func (r *awsApiGatewayResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	priorSchema := resourceSchemaV0()
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &priorSchema,
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var prior resourceModelV0
+				resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+				if resp.Diagnostics.HasError() {
+					return
 				}
-			}
-			if ignoreAccessLogSettings {
-				continue
-			}
-			if stage.AccessLogSettings == nil || stage.AccessLogSettings.DestinationArn == nil {
-				mapDiagnostics.addError(AccessLogNotEnabledREST.new(), apiIdWithStageName)
-			} else {
-				logGroupName := getAccessLogGroupNameFromArn(*(stage.AccessLogSettings.DestinationArn))
-				if verifyAccessLogFormat(*(stage.AccessLogSettings.Format), apiIdWithStageName, logGroupName, accessLogFormatKeysMap, mapDiagnostics) {
-					logGroupNames = append(logGroupNames, logGroupName)
+				state := resourceModel{
+					Id:         prior.Id,
+					Identifier: prior.Identifier,
+					argumentsModel: argumentsModel{
+						IgnoreAccessLogSettings: prior.IgnoreAccessLogSettings,
+						StrictLogGroupFormat:    types.BoolValue(false),
+						Timeout:                 prior.Timeout,
+					},
+					LogGroupNames: prior.LogGroupNames,
 				}
-			}
-		}
+				for _, account := range prior.Accounts {
+					state.Accounts = append(state.Accounts, accountModel{
+						Region:              account.Region,
+						ApiList:             account.ApiList,
+						CrossAccountRoleArn: account.CrossAccountRoleArn,
+						Exclude:             account.Exclude,
+					})
+				}
+				if state.Identifier.IsNull() {
+					state.Identifier = types.StringValue("")
+				}
+				if state.IgnoreAccessLogSettings.IsNull() {
+					state.IgnoreAccessLogSettings = types.BoolValue(false)
+				}
+				if state.Timeout.IsNull() {
+					state.Timeout = types.StringValue("1m")
+				}
+				if state.LogGroupNames.IsNull() {
+					state.LogGroupNames = types.ListValueMust(types.StringType, nil)
+				}
+				state.FailOn = types.StringValue(string(discovery.FailOnAnyError))
+				state.FailOnUnmatched = types.BoolValue(false)
+				state.ReportFormat = types.StringValue(string(discovery.ReportFormatJson))
+				state.FailedAccounts = types.ListValueMust(types.ObjectType{AttrTypes: failedAccountAttrTypes}, nil)
+				state.StageInventory = types.ListValueMust(types.ObjectType{AttrTypes: stageAttrTypes}, nil)
+				state.SelectionExplain = types.ListValueMust(types.ObjectType{AttrTypes: selectionAttrTypes}, nil)
+				resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+			},
+		},
 	}
-	return logGroupNames
 }
 
-func getLogGroupNamesHttpApisHelper(
-	ctx context.Context,
-	conn AwsApiGatewayProvider,
-	apiStageMappingV2 map[string][]string,
-	exclude bool,
-	accessLogFormatKeysMap map[string]AccessLogFormatMap,
-	mapDiagnostics *MapDiagnostics) []string {
-	var logGroupNames []string
-	apiGatewayV2Client := conn.getApiGatewayV2Client()
-	for apiId, apiStages := range apiStageMappingV2 {
-		res, err := apiGatewayV2Client.GetStages(ctx, &v2.GetStagesInput{
-			ApiId: &apiId,
-		})
-		if err != nil {
-			summary := fmt.Sprintf("Error while invoking getStages sdk call: %s", err.Error())
-			mapDiagnostics.add(errorDiagnostic(summary))
-			continue
-		}
-		for _, stage := range res.Items {
-			stageName := *(stage.StageName)
-			apiIdWithStageName := strings.Join([]string{apiId, stageName}, "/")
-			if len(apiStages) > 0 && contains(apiStages, stageName) == exclude {
-				continue
-			}
-			if stage.AccessLogSettings == nil || stage.AccessLogSettings.DestinationArn == nil {
-				mapDiagnostics.addError(AccessLogNotEnabledHTTP.new(), apiIdWithStageName)
-			} else {
-				logGroupName := getAccessLogGroupNameFromArn(*(stage.AccessLogSettings.DestinationArn))
-				if verifyAccessLogFormat(*(stage.AccessLogSettings.Format), apiIdWithStageName, logGroupName, accessLogFormatKeysMap, mapDiagnostics) {
-					logGroupNames = append(logGroupNames, logGroupName)
-				}
-			}
-		}
+// resourceSchemaV0 is the schema of the terraform-plugin-sdk/v2 releases, it must not change.
+func resourceSchemaV0() schema.Schema {
+	return schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			keys.Id:                      schema.StringAttribute{Computed: true},
+			keys.Identifier:              schema.StringAttribute{Optional: true},
+			keys.IgnoreAccessLogSettings: schema.BoolAttribute{Optional: true},
+			keys.LogGroupNames:           schema.ListAttribute{Computed: true, ElementType: types.StringType},
+			keys.Timeout:                 schema.StringAttribute{Optional: true},
+		},
+		Blocks: map[string]schema.Block{
+			keys.Accounts: schema.ListNestedBlock{
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						keys.Region:              schema.StringAttribute{Required: true},
+						keys.ApiList:             schema.ListAttribute{Required: true, ElementType: types.StringType},
+						keys.CrossAccountRoleArn: schema.StringAttribute{Required: true},
+						keys.Exclude:             schema.BoolAttribute{Required: true},
+					},
+				},
+			},
+		},
 	}
-	return logGroupNames
 }
 
-func fixAccessLogFormatMissingQuotes(format string) string {
-	re := regexp.MustCompile(`:\s*(\$context[.\w]*)`)
-	return string(re.ReplaceAll([]byte(format), []byte(":\"$1\"")))
+type resourceModelV0 struct {
+	Id                      types.String     `tfsdk:"id"`
+	Identifier              types.String     `tfsdk:"identifier"`
+	IgnoreAccessLogSettings types.Bool       `tfsdk:"ignore_access_log_settings"`
+	LogGroupNames           types.List       `tfsdk:"log_group_names"`
+	Timeout                 types.String     `tfsdk:"timeout"`
+	Accounts                []accountModelV0 `tfsdk:"accounts"`
 }
 
-func verifyAccessLogFormat(format string, apiIdWithStageName string, logGroupName string,
-	accessLogFormatKeysMap map[string]AccessLogFormatMap, mapDiagnostics *MapDiagnostics) bool {
-
-	var parsed map[string]interface{}
-	fixedFormat := fixAccessLogFormatMissingQuotes(format)
-	if err := json.Unmarshal([]byte(fixedFormat), &parsed); err != nil {
-		if err = json.Unmarshal([]byte("{"+fixedFormat+"}"), &parsed); err != nil {
-			mapDiagnostics.addError(AccessLogFormatNotJson.new(), apiIdWithStageName)
-			return false
-		}
-	}
-
-	var foundValues []string
-	var missingValues []string
-	accessLogKeys := make(map[string]string)
-	for key, value := range Flatten(parsed) {
-		valueStr := value.(string)
-		accessLogKeys[valueStr] = key
-		if contains(AccessLogFormatMandatoryValues, valueStr) {
-			foundValues = append(foundValues, valueStr)
-		}
-	}
-
-	for _, value := range AccessLogFormatMandatoryValues {
-		if !contains(foundValues, value) {
-			missingValues = append(missingValues, value)
-		}
-	}
-	if len(missingValues) > 0 {
-		mapDiagnostics.addError(AccessLogFormatMissingRequiredValues.new(WithMissingValues(missingValues)), apiIdWithStageName)
-		return false
-	}
-	if storedMap, found := accessLogFormatKeysMap[logGroupName]; found {
-		for value, key := range accessLogKeys {
-			if storedKey, valueFound := storedMap.valueToKey[value]; valueFound {
-				if key != storedKey {
-					mapDiagnostics.addWarn(AccessLogFormatKeyMismatch.new(WithLogGroupName(logGroupName)), value)
-				}
-			} else {
-				storedMap.valueToKey[value] = key
-			}
-		}
-	} else {
-		accessLogFormatKeysMap[logGroupName] = AccessLogFormatMap{
-			valueToKey: accessLogKeys,
-		}
-	}
-	return true
+type accountModelV0 struct {
+	Region              types.String   `tfsdk:"region"`
+	ApiList             []types.String `tfsdk:"api_list"`
+	CrossAccountRoleArn types.String   `tfsdk:"cross_account_role_arn"`
+	Exclude             types.Bool     `tfsdk:"exclude"`
 }
@@ -1,103 +1,98 @@
 package provider
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/Traceableai/terraform-provider-awsapigateway/internal/fakeaws"
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestFlatten(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    map[string]interface{}
-		expected map[string]interface{}
-	}{
-		{
-			name:     "empty map",
-			input:    make(map[string]interface{}),
-			expected: make(map[string]interface{}),
-		},
-		{
-			name: "flat json map",
-			input: map[string]interface{}{
-				"stringKey":   "stringValue",
-				"numericKey":  1234,
-				"booleanKey":  true,
-				"floatingKey": 123.4,
-			},
-			expected: map[string]interface{}{
-				"stringKey":   "stringValue",
-				"numericKey":  1234,
-				"booleanKey":  true,
-				"floatingKey": 123.4,
-			},
-		},
-		{
-			name: "nested json map",
-			input: map[string]interface{}{
-				"string.Key": "string.Value",
-				"nestedKey": map[string]interface{}{
-					"level1.a": map[string]interface{}{
-						"numericKey": 1234,
-						"booleanKey": true,
-					},
-					"stringKey": "StringValue",
-				},
-				"floatingKey": 123.4,
-			},
-			expected: map[string]interface{}{
-				"string.Key":                    "string.Value",
-				"nestedKey.level1.a.numericKey": 1234,
-				"nestedKey.level1.a.booleanKey": true,
-				"nestedKey.stringKey":           "StringValue",
-				"floatingKey":                   123.4,
-			},
-		},
-	}
+func TestUpgradeResourceState(t *testing.T) {
+	ctx := context.Background()
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	assert.NoError(t, err)
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			assert.Equal(t, test.expected, Flatten(test.input))
-		})
-	}
+	// state written by terraform-plugin-sdk/v2 releases, before strict_log_group_format and
+	// stage_inventory existed
+	state := `{
+		"id": "6a0c1a4e-5d1f-4c43-9a43-3f1d3c0b8d1e",
+		"identifier": "",
+		"ignore_access_log_settings": false,
+		"log_group_names": ["API-Gateway-Execution-Logs_api1/dev"],
+		"timeout": "1m",
+		"accounts": [{
+			"region": "us-east-1",
+			"api_list": ["api1"],
+			"cross_account_role_arn": "",
+			"exclude": false
+		}]
+	}`
+	resp, err := server.UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: "awsapigateway_resource",
+		Version:  0,
+		RawState: &tfprotov6.RawState{JSON: []byte(state)},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Diagnostics)
+
+	s := resourceSchema()
+	value, err := resp.UpgradedState.Unmarshal(s.Type().TerraformType(ctx))
+	assert.NoError(t, err)
+	var upgraded resourceModel
+	assert.False(t, tfsdk.State{Schema: s, Raw: value}.Get(ctx, &upgraded).HasError())
+	assert.Equal(t, "6a0c1a4e-5d1f-4c43-9a43-3f1d3c0b8d1e", upgraded.Id.ValueString())
+	assert.False(t, upgraded.StrictLogGroupFormat.ValueBool())
+	assert.Len(t, upgraded.LogGroupNames.Elements(), 1)
+	assert.False(t, upgraded.StageInventory.IsNull())
+	assert.Equal(t, string(discovery.FailOnAnyError), upgraded.FailOn.ValueString())
+	assert.True(t, upgraded.Accounts[0].StsRegion.IsNull())
+	assert.Equal(t, []discovery.AccountConfig{{Region: "us-east-1", ApiList: []string{"api1"}}}, upgraded.discoveryConfig().Accounts)
 }
 
-func TestFixAccessLogFormatMissingQuotes(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{
-			name:     "not json",
-			input:    `"key1": "value1", "key2", "key3"`,
-			expected: `"key1": "value1", "key2", "key3"`,
-		},
-		{
-			name:     "flat json: no values with missing quotes",
-			input:    `{"key1":"$context.requestTime", "key2":"$context.path", "key3":"$context.requestId"}`,
-			expected: `{"key1":"$context.requestTime", "key2":"$context.path", "key3":"$context.requestId"}`,
-		},
-		{
-			name:     "nested json: no values with missing quotes",
-			input:    `{"key1": "$context.path", "nested.key":{"level1":"$context.url", "level1b":"$context.Status"}}`,
-			expected: `{"key1": "$context.path", "nested.key":{"level1":"$context.url", "level1b":"$context.Status"}}`,
-		},
-		{
-			name:     "flat json: values with missing quotes",
-			input:    `{"key1":"$context.requestTime", "key2":$context.path, "key3":  $context.requestId}`,
-			expected: `{"key1":"$context.requestTime", "key2":"$context.path", "key3":"$context.requestId"}`,
-		},
-		{
-			name:     "nested json: values with missing quotes",
-			input:    `{"key1": "$context.path"  , "nested.key":{ "level1":  $context.Status, "level1b" :  $context.identity.sourceIp , "level1c":"v"}}`,
-			expected: `{"key1": "$context.path"  , "nested.key":{ "level1":"$context.Status", "level1b" :"$context.identity.sourceIp" , "level1c":"v"}}`,
-		},
-	}
+func TestHasDiscoveryResults(t *testing.T) {
+	imported := newResourceModel(&importConfig{})
+	assert.False(t, imported.hasDiscoveryResults())
+
+	// a discovery selecting no stage saves empty lists, it is not discovered again on read
+	empty := newResourceModel(&importConfig{})
+	assert.Empty(t, empty.setDiscoveryResults(context.Background(), &discovery.Result{}))
+	assert.True(t, empty.hasDiscoveryResults())
+}
+
+func TestApplyDiscoveryResultsReport(t *testing.T) {
+	ctx := context.Background()
+	server := fakeaws.NewServer(t, "testdata/fakeaws.yaml")
+	server.SetCredentials(t)
+	r := &awsApiGatewayResource{providerData: &providerData{
+		clientFactory: discovery.NewClientFactory(discovery.Endpoints{ApiGateway: server.URL, ApiGatewayV2: server.URL, Sts: server.URL}),
+	}}
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	imported, err := parseImportId(`{"report_path":"` + reportPath + `","accounts":[{"region":"us-east-1","include_apis":["pets"],"cross_account_role_arn":""}]}`)
+	assert.NoError(t, err)
+	plan := newResourceModel(imported)
+	result, _ := discover(ctx, plan.discoveryConfig(), r.providerData)
+	assert.NotNil(t, result)
+	assert.Empty(t, plan.setDiscoveryResults(ctx, result))
+
+	var diagnostics diag.Diagnostics
+	assert.True(t, r.applyDiscoveryResults(ctx, plan, &diagnostics))
+	assert.False(t, diagnostics.HasError(), diagnostics)
+	assert.FileExists(t, reportPath)
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			assert.Equal(t, test.expected, fixAccessLogFormatMissingQuotes(test.input))
-		})
-	}
+	// the report would not describe the saved results
+	assert.NoError(t, os.Remove(reportPath))
+	plan.LogGroupNames = types.ListValueMust(types.StringType, nil)
+	diagnostics = nil
+	assert.False(t, r.applyDiscoveryResults(ctx, plan, &diagnostics))
+	assert.True(t, diagnostics.HasError())
+	assert.NoFileExists(t, reportPath)
 }
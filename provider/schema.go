@@ -0,0 +1,217 @@
+package provider
+
+import (
+	"github.com/Traceableai/terraform-provider-awsapigateway/provider/keys"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// The resource and the data source build their schemas from the descriptions below.
+
+type accountArgument struct {
+	attrType         attr.Type
+	required         bool
+	description      string
+	deprecation      string
+	stringValidators []validator.String
+	listValidators   []validator.List
+}
+
+func accountArguments() map[string]accountArgument {
+	return map[string]accountArgument{
+		keys.Region: {
+			attrType:         types.StringType,
+			required:         true,
+			stringValidators: []validator.String{regionValidator(false)},
+		},
+		keys.IncludeApis: {
+			attrType:       types.ListType{ElemType: types.StringType},
+			description:    includeApisDescription,
+			listValidators: []validator.List{selectorEntriesValidator(true)},
+		},
+		keys.ExcludeApis: {
+			attrType:       types.ListType{ElemType: types.StringType},
+			description:    excludeApisDescription,
+			listValidators: []validator.List{selectorEntriesValidator(true)},
+		},
+		keys.ApiList: {
+			attrType:       types.ListType{ElemType: types.StringType},
+			deprecation:    apiListDeprecation,
+			listValidators: apiListValidators(),
+		},
+		keys.CrossAccountRoleArn: {
+			attrType:         types.StringType,
+			required:         true,
+			description:      "Role assumed to read the account, empty to use the credentials of the provider.",
+			stringValidators: []validator.String{roleArnValidator(true)},
+		},
+		keys.StsRegion: {
+			attrType:         types.StringType,
+			description:      stsRegionDescription,
+			stringValidators: []validator.String{regionValidator(false)},
+		},
+		keys.Exclude: {
+			attrType:    types.BoolType,
+			deprecation: excludeDeprecation,
+		},
+	}
+}
+
+func accountsValidators() []validator.List {
+	return []validator.List{
+		listvalidator.IsRequired(),
+		listvalidator.SizeAtLeast(1),
+	}
+}
+
+var resultTypes = map[string]attr.Type{
+	keys.LogGroupNames:    types.ListType{ElemType: types.StringType},
+	keys.StageInventory:   types.ListType{ElemType: types.ObjectType{AttrTypes: stageAttrTypes}},
+	keys.FailedAccounts:   types.ListType{ElemType: types.ObjectType{AttrTypes: failedAccountAttrTypes}},
+	keys.SelectionExplain: types.ListType{ElemType: types.ObjectType{AttrTypes: selectionAttrTypes}},
+}
+
+var resultDescriptions = map[string]string{
+	keys.SelectionExplain: selectionExplainDescription,
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                 resource                                  //
+///////////////////////////////////////////////////////////////////////////////
+
+func resourceAccountsBlock() resourceschema.ListNestedBlock {
+	attributes := make(map[string]resourceschema.Attribute)
+	for name, argument := range accountArguments() {
+		switch argument.attrType.(type) {
+		case basetypes.StringType:
+			attributes[name] = resourceschema.StringAttribute{
+				Required:           argument.required,
+				Optional:           !argument.required,
+				Description:        argument.description,
+				DeprecationMessage: argument.deprecation,
+				Validators:         argument.stringValidators,
+			}
+		case basetypes.BoolType:
+			attributes[name] = resourceschema.BoolAttribute{
+				Required:           argument.required,
+				Optional:           !argument.required,
+				Description:        argument.description,
+				DeprecationMessage: argument.deprecation,
+			}
+		default:
+			attributes[name] = resourceschema.ListAttribute{
+				Required:           argument.required,
+				Optional:           !argument.required,
+				ElementType:        types.StringType,
+				Description:        argument.description,
+				DeprecationMessage: argument.deprecation,
+				Validators:         argument.listValidators,
+			}
+		}
+	}
+	return resourceschema.ListNestedBlock{
+		Validators:   accountsValidators(),
+		NestedObject: resourceschema.NestedBlockObject{Attributes: attributes},
+	}
+}
+
+func resourceResultAttributes(attributes map[string]resourceschema.Attribute) {
+	for name, attrType := range resultTypes {
+		attributes[name] = resourceResultAttribute(attrType, resultDescriptions[name])
+	}
+}
+
+func resourceResultAttribute(attrType attr.Type, description string) resourceschema.Attribute {
+	switch attrType := attrType.(type) {
+	case basetypes.BoolType:
+		return resourceschema.BoolAttribute{Computed: true, Description: description}
+	case basetypes.ListType:
+		object, ok := attrType.ElemType.(basetypes.ObjectType)
+		if !ok {
+			return resourceschema.ListAttribute{Computed: true, ElementType: attrType.ElemType, Description: description}
+		}
+		nested := make(map[string]resourceschema.Attribute, len(object.AttrTypes))
+		for name, nestedType := range object.AttrTypes {
+			nested[name] = resourceResultAttribute(nestedType, "")
+		}
+		return resourceschema.ListNestedAttribute{
+			Computed:     true,
+			Description:  description,
+			NestedObject: resourceschema.NestedAttributeObject{Attributes: nested},
+		}
+	}
+	return resourceschema.StringAttribute{Computed: true, Description: description}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                data source                                //
+///////////////////////////////////////////////////////////////////////////////
+
+func dataSourceAccountsBlock() datasourceschema.ListNestedBlock {
+	attributes := make(map[string]datasourceschema.Attribute)
+	for name, argument := range accountArguments() {
+		switch argument.attrType.(type) {
+		case basetypes.StringType:
+			attributes[name] = datasourceschema.StringAttribute{
+				Required:           argument.required,
+				Optional:           !argument.required,
+				Description:        argument.description,
+				DeprecationMessage: argument.deprecation,
+				Validators:         argument.stringValidators,
+			}
+		case basetypes.BoolType:
+			attributes[name] = datasourceschema.BoolAttribute{
+				Required:           argument.required,
+				Optional:           !argument.required,
+				Description:        argument.description,
+				DeprecationMessage: argument.deprecation,
+			}
+		default:
+			attributes[name] = datasourceschema.ListAttribute{
+				Required:           argument.required,
+				Optional:           !argument.required,
+				ElementType:        types.StringType,
+				Description:        argument.description,
+				DeprecationMessage: argument.deprecation,
+				Validators:         argument.listValidators,
+			}
+		}
+	}
+	return datasourceschema.ListNestedBlock{
+		Validators:   accountsValidators(),
+		NestedObject: datasourceschema.NestedBlockObject{Attributes: attributes},
+	}
+}
+
+func dataSourceResultAttributes(attributes map[string]datasourceschema.Attribute) {
+	for name, attrType := range resultTypes {
+		attributes[name] = dataSourceResultAttribute(attrType, resultDescriptions[name])
+	}
+}
+
+func dataSourceResultAttribute(attrType attr.Type, description string) datasourceschema.Attribute {
+	switch attrType := attrType.(type) {
+	case basetypes.BoolType:
+		return datasourceschema.BoolAttribute{Computed: true, Description: description}
+	case basetypes.ListType:
+		object, ok := attrType.ElemType.(basetypes.ObjectType)
+		if !ok {
+			return datasourceschema.ListAttribute{Computed: true, ElementType: attrType.ElemType, Description: description}
+		}
+		nested := make(map[string]datasourceschema.Attribute, len(object.AttrTypes))
+		for name, nestedType := range object.AttrTypes {
+			nested[name] = dataSourceResultAttribute(nestedType, "")
+		}
+		return datasourceschema.ListNestedAttribute{
+			Computed:     true,
+			Description:  description,
+			NestedObject: datasourceschema.NestedAttributeObject{Attributes: nested},
+		}
+	}
+	return datasourceschema.StringAttribute{Computed: true, Description: description}
+}
@@ -0,0 +1,24 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/provider/keys"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedSchemaAttributes(t *testing.T) {
+	ctx := context.Background()
+	var resp datasource.SchemaResponse
+	NewAwsApiGatewayLogGroupsDataSource().Schema(ctx, datasource.SchemaRequest{}, &resp)
+	resourceAttributes := resourceSchema().GetAttributes()
+	for name, attrType := range resultTypes {
+		assert.Equal(t, attrType, resourceAttributes[name].GetType(), name)
+		assert.Equal(t, attrType, resp.Schema.GetAttributes()[name].GetType(), name)
+	}
+	assert.Equal(t,
+		resourceSchema().GetBlocks()[keys.Accounts].Type(),
+		resp.Schema.GetBlocks()[keys.Accounts].Type())
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Traceableai/terraform-provider-awsapigateway/pkg/discovery"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// discoveryValidator checks a string attribute with one of the Validate functions of discovery.
+type discoveryValidator struct {
+	description string
+	summary     string
+	validate    func(value string) error
+	// allowEmpty accepts the empty string standing for the default
+	allowEmpty bool
+}
+
+var _ validator.String = discoveryValidator{}
+
+func (v discoveryValidator) Description(_ context.Context) string {
+	return v.description
+}
+
+func (v discoveryValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v discoveryValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	value := req.ConfigValue.ValueString()
+	if value == "" && v.allowEmpty {
+		return
+	}
+	if err := v.validate(value); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, v.summary, err.Error())
+	}
+}
+
+func timeoutValidator() validator.String {
+	return discoveryValidator{
+		description: fmt.Sprintf("value must be a duration between %s and %s", discovery.MinTimeout, discovery.MaxTimeout),
+		summary:     "Invalid timeout",
+		validate:    discovery.ValidateTimeout,
+	}
+}
+
+func cacheTtlValidator() validator.String {
+	return discoveryValidator{
+		description: fmt.Sprintf("value must be a duration between 0s and %s", discovery.MaxCacheTtl),
+		summary:     "Invalid cache TTL",
+		validate:    discovery.ValidateCacheTtl,
+	}
+}
+
+func regionValidator(allowEmpty bool) validator.String {
+	return discoveryValidator{
+		description: "value must be an AWS region such as us-east-1",
+		summary:     "Invalid region",
+		validate:    discovery.ValidateRegion,
+		allowEmpty:  allowEmpty,
+	}
+}
+
+func roleArnValidator(allowEmpty bool) validator.String {
+	return discoveryValidator{
+		description: "value must be an IAM role ARN",
+		summary:     "Invalid role ARN",
+		validate:    discovery.ValidateRoleArn,
+		allowEmpty:  allowEmpty,
+	}
+}
+
+func selectorEntriesValidator(patterns bool) validator.List {
+	return listvalidator.ValueStringsAre(discoveryValidator{
+		description: "value must be an api or api/stage",
+		summary:     "Invalid api entry",
+		validate: func(value string) error {
+			return discovery.ValidateSelectorEntry(value, patterns)
+		},
+	})
+}
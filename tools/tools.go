@@ -5,4 +5,6 @@ package tools
 import (
 	// document generation
 	_ "github.com/hashicorp/terraform-plugin-docs/cmd/tfplugindocs"
+	// mock generation
+	_ "github.com/golang/mock/mockgen"
 )